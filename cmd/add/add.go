@@ -0,0 +1,166 @@
+package add
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/spf13/cobra"
+)
+
+const day = 24 * time.Hour
+
+type commandOptions struct {
+	// mutually exclusive flags for date of note
+	date     string
+	daysBack uint
+	tomorrow bool
+	latest   bool
+
+	section string
+}
+
+// CreateAddCmd creates the add subcommand
+func CreateAddCmd(sel *config.Selection) *cobra.Command {
+	cmdOpts := commandOptions{}
+	cmd := &cobra.Command{
+		Use:          "add [text]",
+		Short:        "add text to a note",
+		Long:         "append text to a section of a note without opening an editor, reading from stdin if no text is provided",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := config.Load(*sel)
+			if err != nil {
+				return err
+			}
+			text, err := getText(args, os.Stdin)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			getFiles := func(dir string) ([]string, error) {
+				return file.ListFiles(dir, template.GetLayoutDepth(opts.File.Layout), opts.File.NotesDir)
+			}
+			date, err := getDate(cmdOpts, opts, getFiles, now)
+			if err != nil {
+				return err
+			}
+			return run(opts, cmdOpts, date, prefixTimestamp(text, opts.Add.TimestampFormat, now))
+		},
+	}
+	attachOpts(cmd, &cmdOpts)
+	return cmd
+}
+
+func attachOpts(cmd *cobra.Command, cmdOpts *commandOptions) {
+	flags := cmd.Flags()
+
+	// mutually exclusive flags for date of note
+	flags.StringVar(&cmdOpts.date, "date", "", "date for note to which text is added (defaults to today)")
+	flags.UintVarP(&cmdOpts.daysBack, "days-back", "d", 0, "number of days back from today for note to which text is added (cannot be used with date, tomorrow, or latest flags)")
+	flags.BoolVarP(&cmdOpts.tomorrow, "tomorrow", "t", false, "specify tomorrow as the date for note to which text is added (cannot be used with date, days-back, or latest flags)")
+	flags.BoolVarP(&cmdOpts.latest, "latest", "l", false, "specify the most recent dated note as the note to which text is added (cannot be used with date, days-back, or tomorrow flags)")
+
+	flags.StringVarP(&cmdOpts.section, "section", "s", "", "section to which text is added")
+}
+
+func run(templateOpts config.Opts, cmdOpts commandOptions, date time.Time, text string) error {
+	if cmdOpts.section == "" {
+		return errors.New("section to which text is added must be specified")
+	}
+
+	t := template.NewTemplate(templateOpts, date)
+	rw := file.NewReadWriter()
+	if rw.Exists(t) {
+		err := rw.Read(t)
+		if err != nil {
+			return fmt.Errorf("cannot load template file: %w", err)
+		}
+	}
+
+	err := t.AppendSectionContents(cmdOpts.section, text)
+	if err != nil {
+		return err
+	}
+
+	err = rw.Overwrite(t)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func getDate(cmdOpts commandOptions, templateOpts config.Opts, getFiles func(string) ([]string, error), now time.Time) (time.Time, error) {
+	numSet := 0
+	date := now
+	errMutuallyExclusive := errors.New("only one of [date, days-back, tomorrow, latest] flags may be used")
+
+	if cmdOpts.date != "" {
+		numSet++
+		parsed, err := time.Parse(templateOpts.Cli.TimeFormat, cmdOpts.date)
+		if err != nil {
+			return date, fmt.Errorf("cannot add to note for malformed date [%s]: %w", cmdOpts.date, err)
+		}
+		date = parsed
+	}
+	if cmdOpts.daysBack != 0 {
+		numSet++
+		date = now.Add(-day * time.Duration(cmdOpts.daysBack))
+	}
+	if cmdOpts.tomorrow {
+		numSet++
+		date = now.Add(day)
+	}
+	if cmdOpts.latest {
+		numSet++
+		if numSet > 1 {
+			return date, errMutuallyExclusive
+		}
+		files, err := getFiles(templateOpts.AppDir)
+		if err != nil {
+			return date, err
+		}
+		latest, _ := template.GetLatestTemplateFile(files, now, templateOpts, template.PeriodDay)
+		_, parsed, ok := template.ParseTemplateFileName(latest, templateOpts)
+		if !ok {
+			return date, fmt.Errorf("failed to find latest template file in [%s]", templateOpts.AppDir)
+		}
+		date = parsed
+	}
+
+	if numSet > 1 {
+		return date, errMutuallyExclusive
+	}
+	return date, nil
+}
+
+// getText joins the arguments into the text to be added, reading the text from r if there are no arguments
+func getText(args []string, r io.Reader) (string, error) {
+	text := strings.Join(args, " ")
+	if len(args) == 0 {
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return "", fmt.Errorf("unable to read text from stdin: %w", err)
+		}
+		text = string(raw)
+	}
+
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("no text to add")
+	}
+	return text, nil
+}
+
+func prefixTimestamp(text string, format string, now time.Time) string {
+	if format == "" {
+		return text
+	}
+	return fmt.Sprintf("%s %s", now.Format(format), text)
+}
@@ -0,0 +1,177 @@
+package add
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDate(t *testing.T) {
+	type testCase struct {
+		cmdOpts      commandOptions
+		files        []string
+		now          time.Time
+		expectedDate time.Time
+		shouldErr    bool
+	}
+
+	tests := map[string]testCase{
+		"multiple mutually exclusive flags: date and daysBack set": {
+			cmdOpts: commandOptions{
+				date:     "2020-04-11",
+				daysBack: 2,
+			},
+			now:       time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			shouldErr: true,
+		},
+		"multiple mutually exclusive flags: daysBack and tomorrow set": {
+			cmdOpts: commandOptions{
+				daysBack: 2,
+				tomorrow: true,
+			},
+			now:       time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			shouldErr: true,
+		},
+		"malformed date": {
+			cmdOpts: commandOptions{
+				date: "2020Apr11",
+			},
+			now:       time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			shouldErr: true,
+		},
+		"use date": {
+			cmdOpts: commandOptions{
+				date: "2020-04-11",
+			},
+			now:          time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedDate: time.Date(2020, 4, 11, 0, 0, 0, 0, time.UTC),
+		},
+		"use daysBack": {
+			cmdOpts: commandOptions{
+				daysBack: 2,
+			},
+			now:          time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedDate: time.Date(2020, 4, 10, 0, 0, 0, 0, time.UTC),
+		},
+		"use tomorrow": {
+			cmdOpts: commandOptions{
+				tomorrow: true,
+			},
+			now:          time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedDate: time.Date(2020, 4, 13, 0, 0, 0, 0, time.UTC),
+		},
+		"multiple mutually exclusive flags: date and latest set": {
+			cmdOpts: commandOptions{
+				date:   "2020-04-11",
+				latest: true,
+			},
+			files:     []string{"2020-04-10.txt"},
+			now:       time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			shouldErr: true,
+		},
+		"use latest": {
+			cmdOpts: commandOptions{
+				latest: true,
+			},
+			files: []string{
+				"2020-04-08.txt",
+				"2020-04-10.txt",
+				"2020-04-13.txt",
+				"week-2020-04-06.txt",
+				"archive-Mar2020.txt",
+			},
+			now:          time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedDate: time.Date(2020, 4, 10, 0, 0, 0, 0, time.UTC),
+		},
+		"latest without dated notes": {
+			cmdOpts: commandOptions{
+				latest: true,
+			},
+			files:     []string{"week-2020-04-06.txt"},
+			now:       time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			shouldErr: true,
+		},
+		"default to today": {
+			cmdOpts:      commandOptions{},
+			now:          time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedDate: time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			getFiles := func(dir string) ([]string, error) {
+				return test.files, nil
+			}
+			date, err := getDate(test.cmdOpts, templatetest.GetOpts(), getFiles, test.now)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedDate, date)
+		})
+	}
+}
+
+func TestGetText(t *testing.T) {
+	type testCase struct {
+		args      []string
+		stdin     string
+		expected  string
+		shouldErr bool
+	}
+
+	tests := map[string]testCase{
+		"single argument": {
+			args:     []string{"deployed v2 to staging"},
+			stdin:    "ignored",
+			expected: "deployed v2 to staging",
+		},
+		"multiple arguments": {
+			args:     []string{"deployed", "v2", "to", "staging"},
+			expected: "deployed v2 to staging",
+		},
+		"stdin": {
+			args:     []string{},
+			stdin:    "line1\nline2\n",
+			expected: "line1\nline2",
+		},
+		"empty stdin": {
+			args:      []string{},
+			stdin:     "",
+			shouldErr: true,
+		},
+		"blank argument": {
+			args:      []string{"  "},
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			text, err := getText(test.args, strings.NewReader(test.stdin))
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, text)
+		})
+	}
+}
+
+func TestPrefixTimestamp(t *testing.T) {
+	now := time.Date(2020, 4, 12, 13, 14, 0, 0, time.UTC)
+
+	t.Run("no format", func(t *testing.T) {
+		require.Equal(t, "text", prefixTimestamp("text", "", now))
+	})
+
+	t.Run("format", func(t *testing.T) {
+		require.Equal(t, "[13:14] text", prefixTimestamp("text", "[15:04]", now))
+	})
+}
@@ -1,9 +1,12 @@
 package archive
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/dkaslovsky/textnote/pkg/archive"
@@ -13,22 +16,38 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// reportFormatJSON is the format of a JSON report of an archive run
+const reportFormatJSON = "json"
+
 type commandOptions struct {
 	delete  bool
 	noWrite bool
 	dryRun  bool
+	rebuild bool
+	report  string
+
+	// flags for selecting the dates of notes to archive, replacing the configured age of notes
+	before     string
+	month      string
+	from       string
+	to         string
+	keepLatest uint
+
+	// mutually exclusive flags for completing an interrupted archive run
+	resume   bool
+	rollback bool
 }
 
-// CreateArchiveCmd creates the today subcommand
-func CreateArchiveCmd() *cobra.Command {
+// CreateArchiveCmd creates the archive subcommand
+func CreateArchiveCmd(sel *config.Selection) *cobra.Command {
 	cmdOpts := commandOptions{}
 	cmd := &cobra.Command{
 		Use:          "archive",
 		Short:        "consolidate notes into archive files",
-		Long:         "consolidate notes into monthly archive files",
+		Long:         "consolidate notes into archive files for each configured archive period (monthly by default)",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts, err := config.Load()
+			opts, err := config.Load(*sel)
 			if err != nil {
 				return err
 			}
@@ -44,37 +63,66 @@ func attachOpts(cmd *cobra.Command, cmdOpts *commandOptions) {
 	flags.BoolVarP(&cmdOpts.delete, "delete", "x", false, "delete individual files after archiving")
 	flags.BoolVarP(&cmdOpts.noWrite, "no-write", "n", false, "disable writing archive files (helpful for deleting previously archived files)")
 	flags.BoolVar(&cmdOpts.dryRun, "dry-run", false, "print file names to be deleted instead of performing deletes (other flags are ignored)")
+	flags.StringVar(&cmdOpts.before, "before", "", "archive notes dated before a date regardless of age (cannot be used with month, from, or to flags)")
+	flags.StringVar(&cmdOpts.month, "month", "", "archive notes of a month, formatted as an archive month, regardless of age (cannot be used with before, from, or to flags)")
+	flags.StringVar(&cmdOpts.from, "from", "", "archive notes dated on or after a date regardless of age (cannot be used with before or month flags)")
+	flags.StringVar(&cmdOpts.to, "to", "", "archive notes dated on or before a date regardless of age (cannot be used with before or month flags)")
+	flags.UintVar(&cmdOpts.keepLatest, "keep-latest", 0, "number of most recent notes to keep regardless of date")
+	flags.BoolVar(&cmdOpts.rebuild, "rebuild", false, "regenerate all archive files, removing duplicated contents archived for the same date (cannot be used with no-write flag)")
+	flags.StringVar(&cmdOpts.report, "report", "", "write a report of the archive run to stdout in a machine-readable format (json)")
+	flags.BoolVar(&cmdOpts.resume, "resume", false, "resume an interrupted archive run (cannot be used with rollback flag)")
+	flags.BoolVar(&cmdOpts.rollback, "rollback", false, "roll back an interrupted archive run (cannot be used with resume flag)")
 }
 
 func run(templateOpts config.Opts, cmdOpts commandOptions) error {
-	archiver := archive.NewArchiver(templateOpts, file.NewReadWriter(), time.Now())
-
-	files, err := os.ReadDir(templateOpts.AppDir)
+	if cmdOpts.resume && cmdOpts.rollback {
+		return errors.New("only one of [resume, rollback] flags may be used")
+	}
+	if cmdOpts.rebuild && cmdOpts.noWrite {
+		return errors.New("rebuild flag cannot be used with no-write flag")
+	}
+	if cmdOpts.report != "" && cmdOpts.report != reportFormatJSON {
+		return fmt.Errorf("unsupported report format [%s], the only supported format is [%s]", cmdOpts.report, reportFormatJSON)
+	}
+	// validate date flags before changing any files
+	_, _, _, err := getDateRange(templateOpts, cmdOpts)
 	if err != nil {
 		return err
 	}
 
-	// add template files to archiver
-	for _, f := range files {
-		if f.IsDir() {
-			continue
-		}
-
-		// parse date from template file name, skipping non-template files
-		templateDate, ok := template.ParseTemplateFileName(f.Name(), templateOpts.File)
-		if !ok {
-			continue
-		}
+	report := archive.NewReport()
+	err = archiveFiles(templateOpts, cmdOpts, report)
+	if cmdOpts.report == "" {
+		return err
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	writeErr := report.Write(os.Stdout)
+	if err != nil {
+		return err
+	}
+	return writeErr
+}
 
-		err := archiver.Add(templateDate)
-		if err != nil {
-			log.Printf("skipping unarchivable file [%s]: %s", f.Name(), err)
-			continue
-		}
+// archiveFiles executes an archive run, recording its outcome in a report
+func archiveFiles(templateOpts config.Opts, cmdOpts commandOptions, report *archive.Report) error {
+	// complete an interrupted archive run before starting a new one
+	done, err := completeInterrupted(templateOpts, cmdOpts, report)
+	if err != nil || done {
+		return err
 	}
 
-	// print file names for dry-run
+	// a dry-run does not change any files so it does not require a transaction
 	if cmdOpts.dryRun {
+		archiver, err := archiveAll(templateOpts, cmdOpts, file.NewReadWriter(), false, false, report)
+		report.SetAddedStatus(archive.StatusDryRun)
+		if err != nil {
+			return err
+		}
+		if cmdOpts.report != "" {
+			return nil
+		}
 		files := archiver.GetArchivedFiles()
 		fmt.Printf("running \"archive --delete\" will remove [%d] files\n", len(files))
 		for _, fileName := range files {
@@ -83,30 +131,260 @@ func run(templateOpts config.Opts, cmdOpts commandOptions) error {
 		return nil
 	}
 
-	// write archive files
-	if !cmdOpts.noWrite {
-		err = archiver.Write()
-		if err != nil {
-			return err
+	tx, err := archive.BeginTransaction(templateOpts.AppDir)
+	if err != nil {
+		return err
+	}
+	// write archive files and, when deleting archived files, confirm all contents are archived before deleting anything
+	archiver, err := archiveAll(templateOpts, cmdOpts, tx, !cmdOpts.noWrite, cmdOpts.delete, report)
+	if err != nil {
+		if cmdOpts.delete {
+			err = fmt.Errorf("%w, no files were removed", err)
 		}
+		return rollbackOnErr(tx, err, report)
+	}
+	addedStatus := archive.StatusArchived
+	if cmdOpts.noWrite {
+		addedStatus = archive.StatusNotWritten
 	}
 
 	// return if not deleting archived files
 	if !cmdOpts.delete {
-		return nil
+		report.SetAddedStatus(addedStatus)
+		return tx.Commit()
 	}
 
 	// delete individual archived files
-	numDeleted := 0
-	for _, fileName := range archiver.GetArchivedFiles() {
-		err = os.Remove(fileName)
+	err = tx.SetPending(archiver.GetArchivedFiles())
+	if err != nil {
+		return rollbackOnErr(tx, err, report)
+	}
+	// archive files are verified so removing the archived files either completes the run or can be resumed
+	report.SetAddedStatus(addedStatus)
+	return removePending(tx, templateOpts, report)
+}
+
+// completeInterrupted completes an interrupted archive run as specified by the resume and rollback flags, returning an
+// additional bool indicating that no further archiving should be done
+func completeInterrupted(templateOpts config.Opts, cmdOpts commandOptions, report *archive.Report) (bool, error) {
+	tx, found, err := archive.OpenTransaction(templateOpts.AppDir)
+	if err != nil {
+		return true, err
+	}
+	if !found {
+		if cmdOpts.resume || cmdOpts.rollback {
+			log.Print("no interrupted archive run found")
+			return true, nil
+		}
+		return false, nil
+	}
+
+	if cmdOpts.rollback {
+		err := tx.Rollback()
 		if err != nil {
-			log.Printf("unable to remove file [%s]: %s", fileName, err)
+			return true, fmt.Errorf("failed to roll back interrupted archive run: %w", err)
+		}
+		log.Print("rolled back interrupted archive run")
+		return true, nil
+	}
+
+	if !cmdOpts.resume {
+		return true, errors.New("found an interrupted archive run, rerun the archive command with either the resume or rollback flag")
+	}
+
+	// archive files were verified so the run is resumed by removing the remaining archived files
+	if tx.GetState() == archive.StateRemoving {
+		return true, removePending(tx, templateOpts, report)
+	}
+
+	// archive files were not completely written so the run is resumed by starting over
+	err = tx.Rollback()
+	if err != nil {
+		return true, fmt.Errorf("failed to roll back partially written archive files: %w", err)
+	}
+	log.Print("rolled back partially written archive files, restarting archive run")
+	return false, nil
+}
+
+// archiveAll constructs an Archiver and archives all template files in the application directory, as well as all
+// existing archive files when rebuilding, writing and verifying archives as specified and recording the outcome of
+// each template file and each written archive in a report
+func archiveAll(
+	templateOpts config.Opts,
+	cmdOpts commandOptions,
+	rw archive.ReadWriter,
+	write bool,
+	verify bool,
+	report *archive.Report,
+) (*archive.Archiver, error) {
+	archiver := archive.NewArchiver(templateOpts, rw, time.Now())
+	archiver.SetRebuild(cmdOpts.rebuild)
+
+	start, end, hasDateRange, err := getDateRange(templateOpts, cmdOpts)
+	if err != nil {
+		return nil, err
+	}
+	if hasDateRange {
+		archiver.SetDateRange(start, end)
+	}
+
+	files, err := file.ListFiles(templateOpts.AppDir, template.GetLayoutDepth(templateOpts.File.Layout), templateOpts.File.NotesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse dates from template file names, skipping non-template files
+	dates := map[time.Time]string{}
+	for _, f := range files {
+		period, templateDate, ok := template.ParseTemplateFileName(f, templateOpts)
+		if !ok {
+			continue
+		}
+		// only daily notes are consolidated into archives
+		if period != template.PeriodDay {
 			continue
 		}
-		numDeleted++
+		dates[templateDate] = f
+	}
+
+	if cmdOpts.rebuild {
+		for _, f := range files {
+			// archive files are written to the top level of the application directory
+			if filepath.Dir(f) != "." {
+				continue
+			}
+			section := ""
+			period, archiveDate, ok := template.ParseArchiveFileName(f, templateOpts)
+			if !ok {
+				section, period, archiveDate, ok = template.ParseSectionArchiveFileName(f, templateOpts)
+			}
+			if !ok || period != archiver.GetPeriod() {
+				continue
+			}
+			archiver.AddExisting(archiveDate, section)
+		}
+	}
+
+	// archive template files, with the kept latest files skipped as too recent
+	toArchive := dropLatest(dates, cmdOpts.keepLatest)
+	for _, templateDate := range toArchive {
+		delete(dates, templateDate)
+	}
+	err = archiver.Archive(toArchive, write, verify)
+
+	report.Sources = append(report.Sources, archiver.GetResults()...)
+	for templateDate := range dates {
+		report.Sources = append(report.Sources, archive.SourceResult{
+			File:   template.NewTemplate(templateOpts, templateDate).GetFilePath(),
+			Status: archive.StatusSkippedRecent,
+		})
+	}
+	for _, fileName := range archiver.GetWrittenFiles() {
+		result := archive.ArchiveResult{File: fileName}
+		if info, err := os.Stat(fileName); err == nil {
+			result.Bytes = info.Size()
+		}
+		report.Archives = append(report.Archives, result)
+	}
+	return archiver, err
+}
+
+// getDateRange returns the range [start, end) of dates of notes to archive specified by the date flags, with a
+// zero start or end leaving the range unbounded on that side, and an additional bool indicating if any date flag
+// was used
+func getDateRange(templateOpts config.Opts, cmdOpts commandOptions) (start time.Time, end time.Time, ok bool, err error) {
+	if cmdOpts.before != "" && (cmdOpts.month != "" || cmdOpts.from != "" || cmdOpts.to != "") {
+		return start, end, false, errors.New("before flag cannot be used with [month, from, to] flags")
+	}
+	if cmdOpts.month != "" && (cmdOpts.from != "" || cmdOpts.to != "") {
+		return start, end, false, errors.New("month flag cannot be used with [from, to] flags")
+	}
+
+	if cmdOpts.before != "" {
+		end, err = time.Parse(templateOpts.Cli.TimeFormat, cmdOpts.before)
+		if err != nil {
+			return start, end, false, fmt.Errorf("cannot archive notes before malformed date [%s]: %w", cmdOpts.before, err)
+		}
+		return start, end, true, nil
+	}
+
+	if cmdOpts.month != "" {
+		month, err := time.Parse(templateOpts.Archive.MonthTimeFormat, cmdOpts.month)
+		if err != nil {
+			return start, end, false, fmt.Errorf("cannot archive notes of malformed month [%s]: %w", cmdOpts.month, err)
+		}
+		return template.PeriodMonth.Start(month), template.PeriodMonth.End(month), true, nil
 	}
-	log.Printf("removed [%d] files after archiving", numDeleted)
 
+	if cmdOpts.from != "" {
+		start, err = time.Parse(templateOpts.Cli.TimeFormat, cmdOpts.from)
+		if err != nil {
+			return start, end, false, fmt.Errorf("cannot archive notes from malformed date [%s]: %w", cmdOpts.from, err)
+		}
+		ok = true
+	}
+	if cmdOpts.to != "" {
+		to, err := time.Parse(templateOpts.Cli.TimeFormat, cmdOpts.to)
+		if err != nil {
+			return start, end, false, fmt.Errorf("cannot archive notes to malformed date [%s]: %w", cmdOpts.to, err)
+		}
+		if !start.IsZero() && to.Before(start) {
+			return start, end, false, fmt.Errorf("date of to flag [%s] must not be before date of from flag [%s]", cmdOpts.to, cmdOpts.from)
+		}
+		// the to date is inclusive
+		end = template.PeriodDay.End(to)
+		ok = true
+	}
+	return start, end, ok, nil
+}
+
+// dropLatest returns the sorted dates excluding the specified number of most recent dates
+func dropLatest(dates map[time.Time]string, keepLatest uint) []time.Time {
+	sorted := []time.Time{}
+	for date := range dates {
+		sorted = append(sorted, date)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+	if int(keepLatest) >= len(sorted) {
+		return []time.Time{}
+	}
+	return sorted[:len(sorted)-int(keepLatest)]
+}
+
+// removePending removes the archived files of a transaction and commits the transaction, recording the removed
+// files in a report
+func removePending(tx *archive.Transaction, templateOpts config.Opts, report *archive.Report) error {
+	removed, err := tx.RemovePending()
+	for _, fileName := range removed {
+		file.RemoveEmptyParents(fileName, templateOpts.AppDir)
+		report.Deleted = append(report.Deleted, archive.DeletionResult{File: fileName, Deleted: true})
+	}
+	if err != nil {
+		// pending files that still exist were not removed
+		for _, fileName := range tx.GetPending() {
+			if _, statErr := os.Stat(fileName); statErr == nil {
+				report.Deleted = append(report.Deleted, archive.DeletionResult{File: fileName, Error: err.Error()})
+			}
+		}
+		return fmt.Errorf("%w, rerun the archive command with the resume flag to complete the archive run", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to remove archive journal: %w", err)
+	}
+	log.Printf("removed [%d] files after archiving", len(removed))
 	return nil
 }
+
+// rollbackOnErr rolls back a transaction after an error, recording the files added to archives as rolled back in a
+// report if the rollback succeeds
+func rollbackOnErr(tx *archive.Transaction, err error, report *archive.Report) error {
+	rollbackErr := tx.Rollback()
+	if rollbackErr != nil {
+		return fmt.Errorf("%w (failed to roll back archive run: %s)", err, rollbackErr)
+	}
+	report.SetAddedStatus(archive.StatusRolledBack)
+	return err
+}
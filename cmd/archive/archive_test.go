@@ -0,0 +1,228 @@
+package archive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/archive"
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDateRange(t *testing.T) {
+	type testCase struct {
+		cmdOpts       commandOptions
+		expectedStart time.Time
+		expectedEnd   time.Time
+		expectedOk    bool
+		shouldErr     bool
+	}
+
+	tests := map[string]testCase{
+		"no flags set": {
+			cmdOpts:    commandOptions{},
+			expectedOk: false,
+		},
+		"multiple mutually exclusive flags: before and month set": {
+			cmdOpts: commandOptions{
+				before: "2021-01-12",
+				month:  "Jan2021",
+			},
+			shouldErr: true,
+		},
+		"multiple mutually exclusive flags: before and from set": {
+			cmdOpts: commandOptions{
+				before: "2021-01-12",
+				from:   "2021-01-01",
+			},
+			shouldErr: true,
+		},
+		"multiple mutually exclusive flags: month and to set": {
+			cmdOpts: commandOptions{
+				month: "Jan2021",
+				to:    "2021-01-12",
+			},
+			shouldErr: true,
+		},
+		"malformed before": {
+			cmdOpts: commandOptions{
+				before: "2021Jan12",
+			},
+			shouldErr: true,
+		},
+		"malformed month": {
+			cmdOpts: commandOptions{
+				month: "2021-01",
+			},
+			shouldErr: true,
+		},
+		"to before from": {
+			cmdOpts: commandOptions{
+				from: "2021-01-12",
+				to:   "2021-01-11",
+			},
+			shouldErr: true,
+		},
+		"before": {
+			cmdOpts: commandOptions{
+				before: "2021-01-12",
+			},
+			expectedEnd: time.Date(2021, 1, 12, 0, 0, 0, 0, time.UTC),
+			expectedOk:  true,
+		},
+		"month": {
+			cmdOpts: commandOptions{
+				month: "Dec2020",
+			},
+			expectedStart: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:    true,
+		},
+		"from": {
+			cmdOpts: commandOptions{
+				from: "2021-01-05",
+			},
+			expectedStart: time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC),
+			expectedOk:    true,
+		},
+		"to": {
+			cmdOpts: commandOptions{
+				to: "2021-01-05",
+			},
+			expectedEnd: time.Date(2021, 1, 6, 0, 0, 0, 0, time.UTC),
+			expectedOk:  true,
+		},
+		"from and to on the same date": {
+			cmdOpts: commandOptions{
+				from: "2021-01-05",
+				to:   "2021-01-05",
+			},
+			expectedStart: time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2021, 1, 6, 0, 0, 0, 0, time.UTC),
+			expectedOk:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			start, end, ok, err := getDateRange(templatetest.GetOpts(), test.cmdOpts)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedOk, ok)
+			require.Equal(t, test.expectedStart, start)
+			require.Equal(t, test.expectedEnd, end)
+		})
+	}
+}
+
+func TestDropLatest(t *testing.T) {
+	dates := map[time.Time]string{
+		time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC): "2021-01-03.txt",
+		time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC): "2021-01-01.txt",
+		time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC): "2021-01-02.txt",
+	}
+
+	type testCase struct {
+		keepLatest uint
+		expected   []time.Time
+	}
+
+	tests := map[string]testCase{
+		"keep none": {
+			keepLatest: 0,
+			expected: []time.Time{
+				time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"keep latest": {
+			keepLatest: 2,
+			expected: []time.Time{
+				time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"keep all": {
+			keepLatest: 3,
+			expected:   []time.Time{},
+		},
+		"keep more than all": {
+			keepLatest: 5,
+			expected:   []time.Time{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, dropLatest(dates, test.keepLatest))
+		})
+	}
+}
+
+func TestArchiveFilesReportStatus(t *testing.T) {
+	type testCase struct {
+		cmdOpts          commandOptions
+		expectedStatus   string
+		expectedArchives int
+		shouldErr        bool
+	}
+
+	tests := map[string]testCase{
+		"archive": {
+			cmdOpts:          commandOptions{},
+			expectedStatus:   archive.StatusArchived,
+			expectedArchives: 1,
+		},
+		"archive and delete": {
+			cmdOpts:          commandOptions{delete: true},
+			expectedStatus:   archive.StatusArchived,
+			expectedArchives: 1,
+		},
+		"no-write": {
+			cmdOpts:        commandOptions{noWrite: true},
+			expectedStatus: archive.StatusNotWritten,
+		},
+		"dry-run": {
+			cmdOpts:        commandOptions{dryRun: true, report: reportFormatJSON},
+			expectedStatus: archive.StatusDryRun,
+		},
+		"rolled back": {
+			// deleting without writing fails to verify that the notes were previously archived
+			cmdOpts:        commandOptions{noWrite: true, delete: true},
+			expectedStatus: archive.StatusRolledBack,
+			shouldErr:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			opts.AppDir = t.TempDir()
+			note := template.NewTemplate(opts, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, note.AppendSectionContents("TestSection1", "text"))
+			require.NoError(t, file.NewReadWriter().Overwrite(note))
+
+			report := archive.NewReport()
+			err := archiveFiles(opts, test.cmdOpts, report)
+			if test.shouldErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, []archive.SourceResult{
+				{
+					File:     note.GetFilePath(),
+					Status:   test.expectedStatus,
+					Archives: []string{filepath.Join(opts.AppDir, "archive-Dec2020.txt")},
+				},
+			}, report.Sources)
+			require.Len(t, report.Archives, test.expectedArchives)
+		})
+	}
+}
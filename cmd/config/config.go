@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/dkaslovsky/textnote/pkg/config"
 	"github.com/spf13/cobra"
@@ -18,22 +19,24 @@ type commandOptions struct {
 }
 
 // CreateConfigCmd creates the config subcommand
-func CreateConfigCmd() *cobra.Command {
+func CreateConfigCmd(sel *config.Selection) *cobra.Command {
 	cmdOpts := commandOptions{}
 	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "manage configuration",
 		Long:  "manages the application's configuration",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			configPath := config.GetConfigFilePath()
-
 			if cmdOpts.path {
-				log.Printf("configuration file path: [%s]", configPath)
-				return nil
+				return displayConfigSources(*sel)
+			}
+
+			configPath, err := config.GetConfigFile(*sel)
+			if err != nil {
+				return err
 			}
 
 			if cmdOpts.active {
-				return displayActiveConfig()
+				return displayActiveConfig(*sel)
 			}
 
 			// default
@@ -41,29 +44,120 @@ func CreateConfigCmd() *cobra.Command {
 		},
 	}
 	attachOpts(cmd, &cmdOpts)
-	cmd.AddCommand(CreateConfigUpdateCmd())
+	cmd.AddCommand(CreateConfigUpdateCmd(sel))
+	cmd.AddCommand(CreateConfigValidateCmd(sel))
+	cmd.AddCommand(CreateConfigGetCmd(sel))
+	cmd.AddCommand(CreateConfigSetCmd(sel))
+	cmd.AddCommand(CreateConfigEditCmd(sel))
+	cmd.AddCommand(CreateConfigDiffCmd(sel))
 	return cmd
 }
 
 func attachOpts(cmd *cobra.Command, cmdOpts *commandOptions) {
 	flags := cmd.Flags()
-	flags.BoolVarP(&cmdOpts.path, "path", "p", false, "display path to configuration file")
+	flags.BoolVarP(&cmdOpts.path, "path", "p", false, "display paths to configuration files and the source of each configuration parameter")
 	flags.BoolVarP(&cmdOpts.active, "active", "a", false, "display configuration the application actively uses (includes environment variable configuration)")
-	flags.BoolVarP(&cmdOpts.file, "file", "f", false, "display contents of configuration file (default)")
+	flags.BoolVarP(&cmdOpts.file, "file", "f", false, "display contents of the notebook's configuration file or the file selected by the --config flag (default)")
 }
 
 // CreateConfigUpdateCmd creates the config update subcommand
-func CreateConfigUpdateCmd() *cobra.Command {
+func CreateConfigUpdateCmd(sel *config.Selection) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "update the configuration file with active configuration",
-		Long:  "update the configuration file to match the active configuration",
+		Long:  "update the notebook's configuration file, or the file selected by the --config flag, to match the active configuration at the current version, keeping a backup of the file, and upgrade the other configuration files in the search path to the current version in place",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return config.Update(*sel)
+		},
+	}
+	return cmd
+}
+
+// CreateConfigValidateCmd creates the config validate subcommand
+func CreateConfigValidateCmd(sel *config.Selection) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "validate",
+		Short:        "validate the active configuration",
+		Long:         "validate the active configuration, reporting all problems with the path and line of each misconfigured option in the configuration file",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			problems, err := config.Diagnose(*sel)
+			if err != nil {
+				return err
+			}
+			if len(problems) == 0 {
+				log.Print("configuration is valid")
+				return nil
+			}
+			for _, problem := range problems {
+				log.Print(problem)
+			}
+			return fmt.Errorf("found [%d] configuration problems", len(problems))
+		},
+	}
+	return cmd
+}
+
+// CreateConfigGetCmd creates the config get subcommand
+func CreateConfigGetCmd(sel *config.Selection) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "get <key>",
+		Short:        "display the active value of a configuration parameter",
+		Long:         "display the active value of a configuration parameter identified by a key of dotted names, such as section.names",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := config.Get(*sel, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// CreateConfigSetCmd creates the config set subcommand
+func CreateConfigSetCmd(sel *config.Selection) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "set <key> <value>",
+		Short:        "set a configuration parameter in the configuration file",
+		Long:         "set a configuration parameter identified by a key of dotted names, such as archive.afterDays, in the notebook's configuration file, or the file selected by the --config flag, after validating the resulting configuration (lists and maps are set using yaml such as [a, b] and {a: b})",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return config.Set(*sel, args[0], args[1])
+		},
+	}
+	return cmd
+}
+
+// CreateConfigDiffCmd creates the config diff subcommand
+func CreateConfigDiffCmd(sel *config.Selection) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "diff",
+		Short:        "display differences of the active configuration from the defaults",
+		Long:         "display each configuration parameter with an active value that differs from its default or is set by an environment variable, along with the source of the active value",
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			active, err := getActiveConfigYaml()
+			diffs, err := config.Diff(*sel)
 			if err != nil {
 				return err
 			}
-			return os.WriteFile(config.GetConfigFilePath(), active, 0o644)
+			if len(diffs) == 0 {
+				log.Print("active configuration matches the defaults")
+				return nil
+			}
+			for _, diff := range diffs {
+				log.Printf("%s: %s", diff.Key, diff.Active)
+				log.Printf("  source:  %s", diff.Source)
+				if diff.File != "" {
+					log.Printf("  files:   %s", diff.File)
+				}
+				log.Printf("  default: %s", diff.Default)
+			}
+			return nil
 		},
 	}
 	return cmd
@@ -86,8 +180,30 @@ func displayConfigFile(configPath string) error {
 	return nil
 }
 
-func displayActiveConfig() error {
-	yml, err := getActiveConfigYaml()
+func displayConfigSources(sel config.Selection) error {
+	files, sources, err := config.GetSources(sel)
+	if err != nil {
+		return err
+	}
+
+	log.Print("configuration files (highest precedence first):")
+	for _, f := range files {
+		status := ""
+		if !f.Exists {
+			status = ", not found"
+		}
+		log.Printf("  [%s] (%s%s)", f.Path, f.Source, status)
+	}
+
+	log.Print("configuration parameter sources:")
+	for _, source := range sources {
+		log.Printf("  %s: %s", source.Key, strings.Join(source.From, ", "))
+	}
+	return nil
+}
+
+func displayActiveConfig(sel config.Selection) error {
+	yml, err := getActiveConfigYaml(sel)
 	if err != nil {
 		return err
 	}
@@ -95,8 +211,8 @@ func displayActiveConfig() error {
 	return nil
 }
 
-func getActiveConfigYaml() ([]byte, error) {
-	opts, err := config.Load()
+func getActiveConfigYaml(sel config.Selection) ([]byte, error) {
+	opts, err := config.Load(sel)
 	if err != nil {
 		return []byte{}, err
 	}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/editor"
+	"github.com/spf13/cobra"
+)
+
+const (
+	choiceEdit    = "e"
+	choiceRestore = "r"
+	choiceKeep    = "k"
+)
+
+// CreateConfigEditCmd creates the config edit subcommand
+func CreateConfigEditCmd(sel *config.Selection) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "edit",
+		Short:        "edit the configuration file",
+		Long:         "open the notebook's configuration file, or the file selected by the --config flag, in the editor and validate the configuration after the editor exits, offering to edit again or restore the previous file if the configuration is invalid",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := config.GetConfigFile(*sel)
+			if err != nil {
+				return err
+			}
+
+			// the editor command is read from the configuration unless the configuration to edit is invalid
+			command := ""
+			if opts, err := config.Load(*sel); err == nil {
+				command = opts.Editor.Command
+			}
+			ed, err := editor.GetEditor(command, os.Getenv(editor.EnvEditor))
+			if err != nil {
+				return err
+			}
+			if ed.Default {
+				log.Printf("Environment variable [%s] not set, attempting to use default editor [%s]", editor.EnvEditor, ed.Cmd)
+			}
+			open := func() error {
+				return ed.Open(configFile{path: configPath})
+			}
+			load := func() error {
+				_, err := config.Load(*sel)
+				return err
+			}
+			return editConfig(configPath, open, load, os.Stdin)
+		},
+	}
+	return cmd
+}
+
+// configFile is a configuration file that can be opened in an editor
+type configFile struct {
+	path string
+}
+
+// GetFilePath returns the path of the configuration file
+func (c configFile) GetFilePath() string {
+	return c.path
+}
+
+// GetFileCursorLine returns the line to place the cursor when opening the configuration file
+func (c configFile) GetFileCursorLine() int {
+	return 1
+}
+
+// editConfig opens a configuration file for editing until the configuration loads or the previous file is restored
+// or the invalid file is kept as chosen from input
+func editConfig(configPath string, open func() error, load func() error, in io.Reader) error {
+	previous, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to read configuration file [%s]: %w", configPath, err)
+	}
+	existed := err == nil
+
+	input := bufio.NewReader(in)
+	for {
+		err := open()
+		if err != nil {
+			return fmt.Errorf("unable to open configuration file [%s]: %w", configPath, err)
+		}
+
+		err = load()
+		if err == nil {
+			log.Print("configuration is valid")
+			return nil
+		}
+		logConfigError(err)
+
+		switch getChoice(input) {
+		case choiceEdit:
+			continue
+		case choiceRestore:
+			err := restoreConfig(configPath, previous, existed)
+			if err != nil {
+				return err
+			}
+			log.Printf("restored previous configuration file [%s]", configPath)
+			return nil
+		default:
+			return fmt.Errorf("configuration file [%s] is invalid", configPath)
+		}
+	}
+}
+
+func logConfigError(err error) {
+	problems := config.Problems{}
+	if !errors.As(err, &problems) {
+		log.Printf("configuration is invalid: %s", err)
+		return
+	}
+	log.Print("configuration is invalid:")
+	for _, problem := range problems {
+		log.Printf("  %s", problem)
+	}
+}
+
+// getChoice prompts for whether to edit the configuration again, restore the previous configuration, or keep the
+// invalid configuration, which is chosen if no valid choice is read
+func getChoice(input *bufio.Reader) string {
+	for {
+		log.Printf("[%s]dit again, [%s]estore previous configuration, or [%s]eep invalid configuration? ", choiceEdit, choiceRestore, choiceKeep)
+		line, err := input.ReadString('\n')
+		choice := strings.ToLower(strings.TrimSpace(line))
+		switch choice {
+		case choiceEdit, choiceRestore, choiceKeep:
+			return choice
+		}
+		if err != nil {
+			return choiceKeep
+		}
+	}
+}
+
+func restoreConfig(configPath string, previous []byte, existed bool) error {
+	if !existed {
+		err := os.Remove(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to remove configuration file [%s]: %w", configPath, err)
+		}
+		return nil
+	}
+	current, err := os.ReadFile(configPath)
+	if err == nil && bytes.Equal(current, previous) {
+		return nil
+	}
+	err = os.WriteFile(configPath, previous, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to restore configuration file [%s]: %w", configPath, err)
+	}
+	return nil
+}
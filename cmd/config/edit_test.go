@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditConfig(t *testing.T) {
+	const (
+		previous = "valid: previous\n"
+		valid    = "valid: edited\n"
+		invalid  = "invalid\n"
+	)
+
+	type testCase struct {
+		exists    bool
+		edits     []string // contents saved by each time the editor is opened
+		input     string
+		expected  string // contents of the configuration file after editing, empty if the file is removed
+		shouldErr bool
+	}
+
+	tests := map[string]testCase{
+		"valid edit": {
+			exists:   true,
+			edits:    []string{valid},
+			expected: valid,
+		},
+		"edit again": {
+			exists:   true,
+			edits:    []string{invalid, valid},
+			input:    "e\n",
+			expected: valid,
+		},
+		"invalid choice is prompted again": {
+			exists:   true,
+			edits:    []string{invalid, valid},
+			input:    "x\nE\n",
+			expected: valid,
+		},
+		"restore": {
+			exists:   true,
+			edits:    []string{invalid},
+			input:    "r\n",
+			expected: previous,
+		},
+		"restore after editing again": {
+			exists:   true,
+			edits:    []string{invalid, invalid},
+			input:    "e\nr\n",
+			expected: previous,
+		},
+		"restore missing file": {
+			exists:   false,
+			edits:    []string{invalid},
+			input:    "r\n",
+			expected: "",
+		},
+		"keep": {
+			exists:    true,
+			edits:     []string{invalid},
+			input:     "k\n",
+			expected:  invalid,
+			shouldErr: true,
+		},
+		"keep without input": {
+			exists:    true,
+			edits:     []string{invalid},
+			input:     "",
+			expected:  invalid,
+			shouldErr: true,
+		},
+	}
+
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), ".config.yml")
+			if test.exists {
+				require.NoError(t, os.WriteFile(configPath, []byte(previous), 0o644))
+			}
+
+			opened := 0
+			open := func() error {
+				require.Less(t, opened, len(test.edits), "editor opened too many times")
+				opened++
+				return os.WriteFile(configPath, []byte(test.edits[opened-1]), 0o644)
+			}
+			load := func() error {
+				raw, err := os.ReadFile(configPath)
+				require.NoError(t, err)
+				if !strings.HasPrefix(string(raw), "valid") {
+					return errors.New("invalid configuration")
+				}
+				return nil
+			}
+
+			err := editConfig(configPath, open, load, strings.NewReader(test.input))
+			if test.shouldErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, len(test.edits), opened)
+
+			raw, err := os.ReadFile(configPath)
+			if test.expected == "" {
+				require.True(t, os.IsNotExist(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(raw))
+		})
+	}
+
+	t.Run("editor error", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), ".config.yml")
+		open := func() error { return errors.New("editor failed") }
+		load := func() error { return nil }
+		require.Error(t, editConfig(configPath, open, load, strings.NewReader("")))
+	})
+}
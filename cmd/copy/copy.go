@@ -0,0 +1,170 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/archive"
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/spf13/cobra"
+)
+
+type commandOptions struct {
+	// mutually exclusive flags for source note
+	fromDate string
+	fromNote string
+
+	// mutually exclusive flags for target note
+	toDate string
+	toNote string
+
+	deleteFlagVal int // count of number of times delete flag is passed
+
+	sections []string
+}
+
+// CreateCopyCmd creates the copy subcommand
+func CreateCopyCmd(sel *config.Selection) *cobra.Command {
+	cmdOpts := commandOptions{}
+	cmd := &cobra.Command{
+		Use:          "copy",
+		Short:        "copy sections between notes",
+		Long:         "copy sections between dated and named notes without opening an editor",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := config.Load(*sel)
+			if err != nil {
+				return err
+			}
+			return run(opts, cmdOpts)
+		},
+	}
+	attachOpts(cmd, &cmdOpts)
+	return cmd
+}
+
+func attachOpts(cmd *cobra.Command, cmdOpts *commandOptions) {
+	flags := cmd.Flags()
+
+	// mutually exclusive flags for source note
+	flags.StringVar(&cmdOpts.fromDate, "from", "", "date of note from which to copy sections (cannot be used with from-note flag)")
+	flags.StringVar(&cmdOpts.fromNote, "from-note", "", "name of note from which to copy sections (cannot be used with from flag)")
+
+	// mutually exclusive flags for target note
+	flags.StringVar(&cmdOpts.toDate, "to", "", "date of note to which sections are copied (cannot be used with to-note flag)")
+	flags.StringVar(&cmdOpts.toNote, "to-note", "", "name of note to which sections are copied (cannot be used with to flag)")
+
+	flags.StringSliceVarP(&cmdOpts.sections, "section", "s", []string{}, "section to copy")
+	flags.CountVarP(&cmdOpts.deleteFlagVal, "delete", "x", "delete sections after copy (pass flag twice to also delete empty source note)")
+}
+
+func run(templateOpts config.Opts, cmdOpts commandOptions) error {
+	if len(cmdOpts.sections) == 0 {
+		return errors.New("at least one section to copy must be specified")
+	}
+
+	src, err := getTemplate(templateOpts, cmdOpts.fromDate, cmdOpts.fromNote, "from")
+	if err != nil {
+		return err
+	}
+	tgt, err := getTemplate(templateOpts, cmdOpts.toDate, cmdOpts.toNote, "to")
+	if err != nil {
+		return err
+	}
+	if src.GetFilePath() == tgt.GetFilePath() {
+		return fmt.Errorf("cannot copy from note [%s] to itself", src.GetFilePath())
+	}
+
+	rw := file.NewReadWriter()
+	src, srcArchived, err := readSource(templateOpts, rw, src)
+	if err != nil {
+		return err
+	}
+	if srcArchived && cmdOpts.deleteFlagVal > 0 {
+		return fmt.Errorf("cannot delete sections from archived note [%s], use the unarchive command to restore it first", src.GetFilePath())
+	}
+	if rw.Exists(tgt) {
+		err := rw.Read(tgt)
+		if err != nil {
+			return fmt.Errorf("cannot load target file: %w", err)
+		}
+	}
+
+	for _, sectionName := range cmdOpts.sections {
+		err := tgt.CopySectionContents(src, sectionName)
+		if err != nil {
+			return fmt.Errorf("cannot copy section [%s] from source to target: %w", sectionName, err)
+		}
+	}
+
+	if cmdOpts.deleteFlagVal > 0 {
+		for _, sectionName := range cmdOpts.sections {
+			err := src.DeleteSectionContents(sectionName)
+			if err != nil {
+				return fmt.Errorf("cannot delete section [%s] from source: %w", sectionName, err)
+			}
+		}
+
+		if cmdOpts.deleteFlagVal > 1 && src.IsEmpty() {
+			err = os.Remove(src.GetFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to remove empty source file: %w", err)
+			}
+		} else {
+			err = rw.Overwrite(src)
+			if err != nil {
+				return fmt.Errorf("failed to save changes to source file: %w", err)
+			}
+		}
+	}
+
+	err = rw.Overwrite(tgt)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	log.Printf("copied [%d] sections from [%s] to [%s]", len(cmdOpts.sections), src.GetFilePath(), tgt.GetFilePath())
+	return nil
+}
+
+// getTemplate constructs a dated or named template from a pair of mutually exclusive flag values
+func getTemplate(templateOpts config.Opts, date string, name string, flagName string) (*template.Template, error) {
+	if date != "" && name != "" {
+		return nil, fmt.Errorf("only one of [%s, %s-note] flags may be used", flagName, flagName)
+	}
+	if name != "" {
+		err := template.ValidateNoteName(name)
+		if err != nil {
+			return nil, err
+		}
+		return template.NewNamedTemplate(templateOpts, name), nil
+	}
+	if date == "" {
+		return nil, fmt.Errorf("one of [%s, %s-note] flags must be used", flagName, flagName)
+	}
+	t, err := time.Parse(templateOpts.Cli.TimeFormat, date)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use note for malformed date [%s]: %w", date, err)
+	}
+	return template.NewTemplate(templateOpts, t), nil
+}
+
+// readSource reads the source note of a copy, falling back to the contents archived for the date of a daily note that
+// does not exist, along with an additional bool indicating if the source was read from an archive
+func readSource(templateOpts config.Opts, rw archive.ReadWriter, src *template.Template) (*template.Template, bool, error) {
+	if !rw.Exists(src) {
+		archived, found, err := archive.ReadArchivedNote(templateOpts, rw, src)
+		if err != nil || found {
+			return archived, found, err
+		}
+	}
+	err := rw.Read(src)
+	if err != nil {
+		return src, false, fmt.Errorf("cannot read source file for copy: %w", err)
+	}
+	return src, false, nil
+}
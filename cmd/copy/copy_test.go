@@ -0,0 +1,182 @@
+package copy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTemplate(t *testing.T) {
+	opts := templatetest.GetOpts()
+	today := time.Now().Format(opts.Cli.TimeFormat)
+	todayDate, err := time.Parse(opts.Cli.TimeFormat, today)
+	require.NoError(t, err)
+
+	type testCase struct {
+		date         string
+		name         string
+		expectedPath string
+		shouldErr    bool
+	}
+
+	tests := map[string]testCase{
+		"today": {
+			date:         today,
+			expectedPath: template.NewTemplate(opts, todayDate).GetFilePath(),
+		},
+		"date": {
+			date:         "2021-02-03",
+			expectedPath: filepath.Join(opts.AppDir, "2021-02-03.txt"),
+		},
+		"named note": {
+			name:         "infra-migration",
+			expectedPath: filepath.Join(opts.AppDir, opts.File.NotesDir, "infra-migration.txt"),
+		},
+		"date and name": {
+			date:      "2021-02-03",
+			name:      "infra-migration",
+			shouldErr: true,
+		},
+		"neither date nor name": {
+			shouldErr: true,
+		},
+		"malformed date": {
+			date:      "2021Feb03",
+			shouldErr: true,
+		},
+		"invalid name": {
+			name:      "../infra-migration",
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := getTemplate(opts, test.date, test.name, "from")
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedPath, tmpl.GetFilePath())
+		})
+	}
+}
+
+func TestReadSource(t *testing.T) {
+	opts := templatetest.GetOpts()
+	opts.AppDir = t.TempDir()
+	rw := file.NewReadWriter()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	archivedDate := time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	// existing today's note and named note
+	for _, tmpl := range []*template.Template{
+		template.NewTemplate(opts, today),
+		template.NewNamedTemplate(opts, "infra-migration"),
+	} {
+		require.NoError(t, tmpl.AppendSectionContents("TestSection1", "existing text"))
+		require.NoError(t, rw.Overwrite(tmpl))
+	}
+
+	// archived note without an existing file
+	archived := template.NewTemplate(opts, archivedDate)
+	require.NoError(t, archived.AppendSectionContents("TestSection1", "archived text"))
+	archive := template.NewArchiveTemplate(opts, template.PeriodMonth, archivedDate)
+	require.NoError(t, archive.ArchiveSectionContents(archived, "TestSection1"))
+	require.NoError(t, rw.Overwrite(archive))
+
+	type testCase struct {
+		src              *template.Template
+		expectedText     string
+		expectedArchived bool
+		shouldErr        bool
+	}
+
+	tests := map[string]testCase{
+		"today's note": {
+			src:          template.NewTemplate(opts, today),
+			expectedText: "existing text",
+		},
+		"named note": {
+			src:          template.NewNamedTemplate(opts, "infra-migration"),
+			expectedText: "existing text",
+		},
+		"archived note": {
+			src:              template.NewTemplate(opts, archivedDate),
+			expectedText:     "archived text",
+			expectedArchived: true,
+		},
+		"note neither existing nor archived": {
+			src:       template.NewTemplate(opts, archivedDate.AddDate(0, 0, 1)),
+			shouldErr: true,
+		},
+		"named note not existing": {
+			src:       template.NewNamedTemplate(opts, "missing"),
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			src, isArchived, err := readSource(opts, rw, test.src)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedArchived, isArchived)
+			require.Equal(t, test.src.GetFilePath(), src.GetFilePath())
+
+			tgt := template.NewNamedTemplate(opts, "target")
+			require.NoError(t, tgt.CopySectionContents(src, "TestSection1"))
+			written := &strings.Builder{}
+			require.NoError(t, tgt.Write(written))
+			require.Contains(t, written.String(), test.expectedText)
+		})
+	}
+}
+
+func TestRunCopyToItself(t *testing.T) {
+	type testCase struct {
+		cmdOpts commandOptions
+	}
+
+	tests := map[string]testCase{
+		"dated note": {
+			cmdOpts: commandOptions{
+				fromDate: "2021-02-03",
+				toDate:   "2021-02-03",
+				sections: []string{"TestSection1"},
+			},
+		},
+		"named note": {
+			cmdOpts: commandOptions{
+				fromNote: "infra-migration",
+				toNote:   "infra-migration",
+				sections: []string{"TestSection1"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			opts.AppDir = t.TempDir()
+
+			err := run(opts, test.cmdOpts)
+			require.ErrorContains(t, err, "to itself")
+
+			files, err := file.ListFiles(opts.AppDir, -1)
+			require.NoError(t, err)
+			require.Empty(t, files)
+		})
+	}
+}
@@ -7,13 +7,13 @@ import (
 )
 
 // CreateInitCmd creates the init subcommand
-func CreateInitCmd() *cobra.Command {
+func CreateInitCmd(sel *config.Selection) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "initialize the application",
 		Long:  "initialize the application's required directories and files",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return config.InitApp()
+			return config.InitApp(*sel)
 		},
 	}
 	return cmd
@@ -0,0 +1,68 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/spf13/cobra"
+)
+
+// CreateNotesCmd creates the notes subcommand
+func CreateNotesCmd(sel *config.Selection) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "notes",
+		Short:        "list named notes",
+		Long:         "list the names of notes that are outside of the date scheme",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := config.Load(*sel)
+			if err != nil {
+				return err
+			}
+			return run(opts)
+		},
+	}
+	return cmd
+}
+
+func run(templateOpts config.Opts) error {
+	names, err := getNoteNames(filepath.Join(templateOpts.AppDir, templateOpts.File.NotesDir), templateOpts.File)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func getNoteNames(dir string, opts config.FileOpts) ([]string, error) {
+	names := []string{}
+
+	dirItems, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		// no named notes have been created
+		return names, nil
+	}
+	if err != nil {
+		return names, err
+	}
+
+	for _, item := range dirItems {
+		if item.IsDir() {
+			continue
+		}
+		name, ok := template.ParseNoteFileName(item.Name(), opts)
+		if !ok {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,79 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNoteNames(t *testing.T) {
+	type testCase struct {
+		files    []string
+		dirs     []string
+		ext      string
+		expected []string
+	}
+
+	tests := map[string]testCase{
+		"no notes directory": {
+			ext:      "txt",
+			expected: []string{},
+		},
+		"empty notes directory": {
+			ext:      "txt",
+			dirs:     []string{"."},
+			expected: []string{},
+		},
+		"named notes sorted by name": {
+			ext:      "txt",
+			files:    []string{"ideas.txt", "infra-migration.txt", "backlog.txt"},
+			expected: []string{"backlog", "ideas", "infra-migration"},
+		},
+		"other files are skipped": {
+			ext: "txt",
+			files: []string{
+				"ideas.txt",
+				"ideas.md",
+				".hidden.txt",
+				".txt",
+				"README",
+			},
+			expected: []string{"ideas"},
+		},
+		"subdirectories are skipped": {
+			ext:      "txt",
+			files:    []string{"ideas.txt", "drafts/plan.txt"},
+			dirs:     []string{"drafts", "old.txt"},
+			expected: []string{"ideas"},
+		},
+		"no extension": {
+			files:    []string{"ideas", "infra-migration.txt"},
+			ext:      "",
+			expected: []string{"ideas", "infra-migration.txt"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts().File
+			opts.Ext = test.ext
+			dir := filepath.Join(t.TempDir(), opts.NotesDir)
+
+			for _, d := range test.dirs {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, d), 0o755))
+			}
+			for _, f := range test.files {
+				path := filepath.Join(dir, f)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte{}, 0o644))
+			}
+
+			names, err := getNoteNames(dir, opts)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, names)
+		})
+	}
+}
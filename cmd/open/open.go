@@ -3,11 +3,11 @@ package open
 import (
 	"fmt"
 	"log"
-	"math"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
+	"github.com/dkaslovsky/textnote/pkg/archive"
 	"github.com/dkaslovsky/textnote/pkg/config"
 	"github.com/dkaslovsky/textnote/pkg/editor"
 	"github.com/dkaslovsky/textnote/pkg/file"
@@ -25,19 +25,33 @@ type commandOptions struct {
 	tomorrow bool
 	latest   bool
 
+	// mutually exclusive flags for period of note to open
+	week   bool
+	month  bool
+	period template.Period
+
+	// name of a note outside of the date scheme (cannot be used with flags for date or period)
+	name string
+
 	// mutually exclusive flags for copy date
 	copyDate     string
 	copyDaysBack uint
 
+	// restore a note from its archived contents instead of opening a read-only view of them
+	restore bool
+
 	deleteFlagVal  int  // count of number of times delete flag is passed
 	deleteSections bool // delete sections on copy (deleteFlagVal > 0)
 	deleteEmpty    bool // delete file if empty after deleting sections (deleteFlagVal > 1)
 
 	sections []string
+
+	// section at which to place the cursor, overriding the configured cursor section
+	cursorSection string
 }
 
 // CreateOpenCmd creates the open subcommand
-func CreateOpenCmd() *cobra.Command {
+func CreateOpenCmd(sel *config.Selection) *cobra.Command {
 	cmdOpts := commandOptions{}
 	cmd := &cobra.Command{
 		Use:          "open",
@@ -45,16 +59,25 @@ func CreateOpenCmd() *cobra.Command {
 		Long:         "open or create a note template",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts, err := config.Load()
+			opts, err := config.Load(*sel)
+			if err != nil {
+				return err
+			}
+			err = setPeriodOpt(&cmdOpts)
+			if err != nil {
+				return err
+			}
+			err = validateNameOpt(cmdOpts)
 			if err != nil {
 				return err
 			}
 			now := time.Now()
-			numFilesSearchedForDate, err := setDateOpt(&cmdOpts, opts, getDirFiles, now)
+			getFiles := getTemplateFilesGetter(opts)
+			numFilesSearchedForDate, err := setDateOpt(&cmdOpts, opts, getFiles, now)
 			if err != nil {
 				return err
 			}
-			numFilesSearchedForCopy, err := setCopyDateOpt(&cmdOpts, opts, getDirFiles, now)
+			numFilesSearchedForCopy, err := setCopyDateOpt(&cmdOpts, opts, getFiles, now)
 			if err != nil {
 				return err
 			}
@@ -76,12 +99,46 @@ func attachOpts(cmd *cobra.Command, cmdOpts *commandOptions) {
 	flags.BoolVarP(&cmdOpts.tomorrow, "tomorrow", "t", false, "specify tomorrow as the date for note to be opened (cannot be used with date, days-back, or latest flags)")
 	flags.BoolVarP(&cmdOpts.latest, "latest", "l", false, "specify the most recent dated note to be opened (cannot be used with date, days-back, or tomorrow flags)")
 
+	// mutually exclusive flags for period of note to open
+	flags.BoolVarP(&cmdOpts.week, "week", "w", false, "open the weekly note for the week containing the date (cannot be used with month flag)")
+	flags.BoolVarP(&cmdOpts.month, "month", "m", false, "open the monthly note for the month containing the date (cannot be used with week flag)")
+
+	flags.StringVar(&cmdOpts.name, "name", "", "name of a note outside of the date scheme to be opened (cannot be used with date or period flags)")
+	flags.BoolVar(&cmdOpts.restore, "restore", false, "restore a previously archived note as an editable note instead of opening a read-only view")
+
 	// mutually exclusive flags for copy date
 	flags.StringVar(&cmdOpts.copyDate, "copy", "", "date of note for copying sections (defaults to date of most recent note, cannot be used with copy-back flag)")
 	flags.UintVarP(&cmdOpts.copyDaysBack, "copy-back", "c", 0, "number of days back from today for copying from a note (cannot be used with copy flag)")
 
 	flags.StringSliceVarP(&cmdOpts.sections, "section", "s", []string{}, "section to copy (defaults to none)")
 	flags.CountVarP(&cmdOpts.deleteFlagVal, "delete", "x", "delete sections after copy (pass flag twice to also delete empty source note)")
+
+	flags.StringVar(&cmdOpts.cursorSection, "cursor-section", "", "section at which to place the cursor (defaults to the configured cursor section or line)")
+}
+
+func setPeriodOpt(cmdOpts *commandOptions) error {
+	if cmdOpts.week && cmdOpts.month {
+		return errors.New("only one of [week, month] flags may be used")
+	}
+
+	cmdOpts.period = template.PeriodDay
+	if cmdOpts.week {
+		cmdOpts.period = template.PeriodWeek
+	}
+	if cmdOpts.month {
+		cmdOpts.period = template.PeriodMonth
+	}
+	return nil
+}
+
+func validateNameOpt(cmdOpts commandOptions) error {
+	if cmdOpts.name == "" {
+		return nil
+	}
+	if cmdOpts.date != "" || cmdOpts.daysBack != 0 || cmdOpts.tomorrow || cmdOpts.latest || cmdOpts.week || cmdOpts.month {
+		return errors.New("name flag cannot be used with [date, days-back, tomorrow, latest, week, month] flags")
+	}
+	return template.ValidateNoteName(cmdOpts.name)
 }
 
 func setDateOpt(cmdOpts *commandOptions, templateOpts config.Opts, getFiles func(string) ([]string, error), now time.Time) (int, error) {
@@ -119,14 +176,11 @@ func setDateOpt(cmdOpts *commandOptions, templateOpts config.Opts, getFiles func
 			return numFiles, err
 		}
 		var latest string
-		latest, numFiles = getLatestTemplateFile(files, now, templateOpts.File)
+		latest, numFiles = template.GetLatestTemplateFile(files, now, templateOpts, cmdOpts.period)
 		if latest == "" {
 			return numFiles, fmt.Errorf("failed to find latest template file in [%s]", templateOpts.AppDir)
 		}
-		if templateOpts.File.Ext != "" {
-			latest = strings.TrimSuffix(latest, fmt.Sprintf(".%s", templateOpts.File.Ext))
-		}
-		date = latest
+		date = formatTemplateFileDate(latest, templateOpts)
 	}
 
 	// default to today
@@ -161,11 +215,8 @@ func setCopyDateOpt(cmdOpts *commandOptions, templateOpts config.Opts, getFiles
 	if err != nil {
 		return numFiles, err
 	}
-	latest, numFiles := getLatestTemplateFile(files, now, templateOpts.File)
-	if templateOpts.File.Ext != "" {
-		latest = strings.TrimSuffix(latest, fmt.Sprintf(".%s", templateOpts.File.Ext))
-	}
-	cmdOpts.copyDate = latest
+	latest, numFiles := template.GetLatestTemplateFile(files, now, templateOpts, cmdOpts.period)
+	cmdOpts.copyDate = formatTemplateFileDate(latest, templateOpts)
 
 	return numFiles, nil
 }
@@ -181,17 +232,49 @@ func run(templateOpts config.Opts, cmdOpts commandOptions) error {
 		return fmt.Errorf("cannot create note for malformed date [%s]: %w", cmdOpts.date, err)
 	}
 
-	t := template.NewTemplate(templateOpts, date)
+	if cmdOpts.cursorSection != "" {
+		templateOpts.File.CursorSection = cmdOpts.cursorSection
+	}
+
+	t := template.NewPeriodTemplate(templateOpts, cmdOpts.period, date)
+	if cmdOpts.name != "" {
+		t = template.NewNamedTemplate(templateOpts, cmdOpts.name)
+	}
+	if cmdOpts.cursorSection != "" && !t.HasSection(cmdOpts.cursorSection) {
+		return fmt.Errorf("cannot place cursor in undefined section [%s]", cmdOpts.cursorSection)
+	}
 	rw := file.NewReadWriter()
-	ed := editor.GetEditor(os.Getenv(editor.EnvEditor))
+	ed, err := editor.GetEditor(templateOpts.Editor.Command, os.Getenv(editor.EnvEditor))
+	if err != nil {
+		return err
+	}
 
 	// open file if no sections to copy
 	if len(cmdOpts.sections) == 0 {
 		if !rw.Exists(t) {
-			err := rw.Overwrite(t)
+			archived, found, err := archive.ReadArchivedNote(templateOpts, rw, t)
 			if err != nil {
 				return err
 			}
+			if found && !cmdOpts.restore {
+				return openReadOnly(archived, ed)
+			}
+			if found {
+				t = archived
+				log.Printf("restoring archived note [%s]", t.GetFilePath())
+			} else {
+				err = carryForward(templateOpts, cmdOpts, rw, t)
+				if err != nil {
+					return err
+				}
+				t.AddDefaultContents()
+			}
+			err = rw.Overwrite(t)
+			if err != nil {
+				return err
+			}
+		} else {
+			readForCursor(templateOpts, rw, t)
 		}
 		return openInEditor(t, ed)
 	}
@@ -200,20 +283,35 @@ func run(templateOpts config.Opts, cmdOpts commandOptions) error {
 	if cmdOpts.copyDate == "" {
 		return fmt.Errorf("cannot find note to copy, [%s] might be empty", templateOpts.AppDir)
 	}
-	if cmdOpts.copyDate == cmdOpts.date {
-		return fmt.Errorf("copying from note dated [%s] not allowed when writing to note for date [%s]", cmdOpts.copyDate, cmdOpts.date)
-	}
 	copyDate, err := time.Parse(templateOpts.Cli.TimeFormat, cmdOpts.copyDate)
 	if err != nil {
 		return fmt.Errorf("cannot copy note from malformed date [%s]: %w", cmdOpts.copyDate, err)
 	}
-	src := template.NewTemplate(templateOpts, copyDate)
-	err = rw.Read(src)
-	if err != nil {
-		return fmt.Errorf("cannot read source file for copy: %w", err)
+	src := template.NewPeriodTemplate(templateOpts, cmdOpts.period, copyDate)
+	if src.GetFilePath() == t.GetFilePath() {
+		return fmt.Errorf("copying from note dated [%s] not allowed when writing to note for date [%s]", cmdOpts.copyDate, cmdOpts.date)
+	}
+	srcArchived := false
+	if !rw.Exists(src) {
+		src, srcArchived, err = archive.ReadArchivedNote(templateOpts, rw, src)
+		if err != nil {
+			return err
+		}
+		if !srcArchived {
+			return fmt.Errorf("cannot read source file for copy: note dated [%s] does not exist", cmdOpts.copyDate)
+		}
+		if cmdOpts.deleteSections {
+			return fmt.Errorf("cannot delete sections from archived note dated [%s], use the unarchive command to restore it first", cmdOpts.copyDate)
+		}
+	} else {
+		err = rw.Read(src)
+		if err != nil {
+			return fmt.Errorf("cannot read source file for copy: %w", err)
+		}
 	}
 	// load template contents if it exists
-	if rw.Exists(t) {
+	exists := rw.Exists(t)
+	if exists {
 		err := rw.Read(t)
 		if err != nil {
 			return fmt.Errorf("cannot load template file: %w", err)
@@ -224,6 +322,9 @@ func run(templateOpts config.Opts, cmdOpts commandOptions) error {
 	if err != nil {
 		return err
 	}
+	if !exists {
+		t.AddDefaultContents()
+	}
 
 	if cmdOpts.deleteSections {
 		err = deleteSections(src, cmdOpts.sections)
@@ -272,57 +373,124 @@ func deleteSections(t *template.Template, sectionNames []string) error {
 	return nil
 }
 
-func openInEditor(t *template.Template, ed *editor.Editor) error {
-	if t.GetFileCursorLine() > 1 && !ed.Supported {
-		log.Printf("Editor [%s] only supported with its default arguments, additional configuration ignored", ed.Cmd)
+// carryForward copies and moves the contents of the sections configured to be carried forward into a new daily note
+// from the note to copy from, which must be an earlier note that is not archived
+func carryForward(templateOpts config.Opts, cmdOpts commandOptions, rw *file.ReadWriter, t *template.Template) error {
+	copied, moved := templateOpts.Section.GetCarryForwardSections()
+	if len(copied)+len(moved) == 0 || t.GetPeriod() != template.PeriodDay || t.GetName() != "" || cmdOpts.copyDate == "" {
+		return nil
 	}
-	if ed.Default {
-		log.Printf("Environment variable [%s] not set, attempting to use default editor [%s]", editor.EnvEditor, ed.Cmd)
+	copyDate, err := time.Parse(templateOpts.Cli.TimeFormat, cmdOpts.copyDate)
+	if err != nil {
+		return fmt.Errorf("cannot carry forward from note for malformed date [%s]: %w", cmdOpts.copyDate, err)
+	}
+	src := template.NewTemplate(templateOpts, copyDate)
+	if !src.GetDate().Before(t.GetDate()) || !rw.Exists(src) {
+		return nil
+	}
+	err = rw.Read(src)
+	if err != nil {
+		return fmt.Errorf("cannot read note to carry forward from: %w", err)
 	}
-	return ed.Open(t)
-}
-
-func getLatestTemplateFile(files []string, now time.Time, opts config.FileOpts) (string, int) {
-	latest := ""
-	delta := math.Inf(1)
-	numTemplateFiles := 0
 
-	for _, f := range files {
-		fileTime, ok := template.ParseTemplateFileName(f, opts)
-		if !ok {
-			// skip archive files and other non-template files that cannot be parsed
-			continue
-		}
-		numTemplateFiles++
-		curdelta := now.Sub(fileTime).Hours()
-		if curdelta < 0 {
-			continue
+	err = copySections(src, t, append(copied, moved...))
+	if err != nil {
+		return err
+	}
+	if len(moved) > 0 {
+		err = deleteSections(src, moved)
+		if err != nil {
+			return fmt.Errorf("failed to remove carried forward section content from source file: %w", err)
 		}
-		if curdelta < delta {
-			delta = curdelta
-			latest = f
+		err = rw.Overwrite(src)
+		if err != nil {
+			return fmt.Errorf("failed to save changes to source file: %w", err)
 		}
 	}
+	log.Printf("carried forward sections from note [%s]", src.GetFilePath())
+	return nil
+}
+
+// readForCursor reads the contents of a note that are needed to place the cursor in a section, leaving the cursor at
+// the configured line if the note cannot be read
+func readForCursor(templateOpts config.Opts, rw *file.ReadWriter, t *template.Template) {
+	if templateOpts.GetCursorSection() == "" {
+		return
+	}
+	err := rw.Read(t)
+	if err != nil {
+		log.Printf("cannot place cursor in section [%s]: %s", templateOpts.GetCursorSection(), err)
+	}
+}
 
-	return latest, numTemplateFiles
+// readOnlyNote is a note written to a temporary read-only file
+type readOnlyNote struct {
+	*template.Template
+	filePath string
 }
 
-func getDirFiles(dir string) ([]string, error) {
-	fileNames := []string{}
+// GetFilePath returns the path of the temporary file
+func (n *readOnlyNote) GetFilePath() string {
+	return n.filePath
+}
 
-	dirItems, err := os.ReadDir(dir)
+// openReadOnly opens a read-only view of a note in a temporary file that is removed after the editor exits
+func openReadOnly(t *template.Template, ed *editor.Editor) error {
+	tmp, err := os.CreateTemp("", fmt.Sprintf("textnote-archived-*-%s", filepath.Base(t.GetFilePath())))
 	if err != nil {
-		return fileNames, err
+		return fmt.Errorf("cannot create read-only view of archived note: %w", err)
 	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
 
-	for _, item := range dirItems {
-		if item.IsDir() {
-			continue
-		}
-		fileNames = append(fileNames, item.Name())
+	note := &readOnlyNote{
+		Template: t,
+		filePath: tmp.Name(),
+	}
+	err = file.NewReadWriter().Overwrite(note)
+	if err != nil {
+		return fmt.Errorf("cannot create read-only view of archived note: %w", err)
 	}
+	err = os.Chmod(note.GetFilePath(), 0o444)
+	if err != nil {
+		return fmt.Errorf("cannot create read-only view of archived note: %w", err)
+	}
+
+	log.Printf("opening read-only view of archived note [%s], use the restore flag to restore it", t.GetFilePath())
+	return openInEditor(note, ed)
+}
+
+// openable is a note that can be opened in an editor
+type openable interface {
+	GetFilePath() string
+	GetFileCursorLine() int
+}
 
-	return fileNames, nil
+func openInEditor(t openable, ed *editor.Editor) error {
+	if t.GetFileCursorLine() > 1 && !ed.Supported {
+		log.Printf("Editor [%s] only supported with its default arguments, additional configuration ignored (set editor.command to place the cursor)", ed.Cmd)
+	}
+	if ed.Default {
+		log.Printf("Environment variable [%s] not set, attempting to use default editor [%s]", editor.EnvEditor, ed.Cmd)
+	}
+	return ed.Open(t)
+}
+
+// formatTemplateFileDate formats the date parsed from a template file name for use as a CLI date
+func formatTemplateFileDate(fileName string, opts config.Opts) string {
+	_, fileTime, ok := template.ParseTemplateFileName(fileName, opts)
+	if !ok {
+		return ""
+	}
+	return fileTime.Format(opts.Cli.TimeFormat)
+}
+
+// getTemplateFilesGetter returns a function listing the files of a directory, and those of its subdirectories
+// nested up to the depth of the configured layout, in which template files might be found
+func getTemplateFilesGetter(opts config.Opts) func(string) ([]string, error) {
+	return func(dir string) ([]string, error) {
+		return file.ListFiles(dir, template.GetLayoutDepth(opts.File.Layout), opts.File.NotesDir)
+	}
 }
 
 func warnTooManyTemplateFiles(n int, thresh int) {
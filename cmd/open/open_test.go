@@ -4,96 +4,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dkaslovsky/textnote/pkg/template"
 	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
 	"github.com/stretchr/testify/require"
 )
 
-func TestGetLatestTemplateFile(t *testing.T) {
-	opts := templatetest.GetOpts()
-
-	type testCase struct {
-		files            []string
-		now              time.Time
-		expectedLatest   string
-		expectedNumFound int
-	}
-
-	tests := map[string]testCase{
-		"empty directory": {
-			files:            []string{},
-			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
-			expectedLatest:   "",
-			expectedNumFound: 0,
-		},
-		"no timestamped template files": {
-			files: []string{
-				"archive-Dec2019.txt",
-				"archive-2019-11-01.txt",
-			},
-			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
-			expectedLatest:   "",
-			expectedNumFound: 0,
-		},
-		"single template file in future": {
-			files: []string{
-				"2020-04-13.txt",
-			},
-			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
-			expectedLatest:   "",
-			expectedNumFound: 1,
-		},
-		"single template file": {
-			files: []string{
-				"2020-03-11.txt",
-			},
-			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
-			expectedLatest:   "2020-03-11.txt",
-			expectedNumFound: 1,
-		},
-		"multiple template files": {
-			files: []string{
-				"2020-03-11.txt",
-				"2020-03-12.txt",
-				"2020-03-13.txt",
-			},
-			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
-			expectedLatest:   "2020-03-13.txt",
-			expectedNumFound: 3,
-		},
-		"multiple template files with one in future": {
-			files: []string{
-				"2020-04-11.txt",
-				"2020-04-12.txt",
-				"2020-04-13.txt",
-			},
-			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
-			expectedLatest:   "2020-04-12.txt",
-			expectedNumFound: 3,
-		},
-		"mix of timestamped template files and other files": {
-			files: []string{
-				".config",
-				"foobar",
-				"2020-03-11.txt",
-				"2020-03-12.txt",
-				"2020-03-13.txt",
-				"archive_April2020",
-			},
-			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
-			expectedLatest:   "2020-03-13.txt",
-			expectedNumFound: 3,
-		},
-	}
-
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			latest, numFound := getLatestTemplateFile(test.files, test.now, opts.File)
-			require.Equal(t, test.expectedLatest, latest)
-			require.Equal(t, test.expectedNumFound, numFound)
-		})
-	}
-}
-
 func TestSetDateOpt(t *testing.T) {
 	type testCase struct {
 		cmdOpts          *commandOptions
@@ -239,6 +154,21 @@ func TestSetDateOpt(t *testing.T) {
 			expectedNumFiles: 3,
 			shouldErr:        false,
 		},
+		"use latest weekly": {
+			cmdOpts: &commandOptions{
+				latest: true,
+				period: template.PeriodWeek,
+			},
+			files: []string{
+				"2020-04-11.txt",
+				"week-2020-03-30.txt",
+				"week-2020-04-06.txt",
+			},
+			now:              time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC),
+			expectedDate:     "2020-04-06",
+			expectedNumFiles: 3,
+			shouldErr:        false,
+		},
 		"no latest found": {
 			cmdOpts: &commandOptions{
 				latest: true,
@@ -377,6 +307,120 @@ func TestSetCopyDateOpt(t *testing.T) {
 	}
 }
 
+func TestSetPeriodOpt(t *testing.T) {
+	type testCase struct {
+		cmdOpts        *commandOptions
+		expectedPeriod template.Period
+		shouldErr      bool
+	}
+
+	tests := map[string]testCase{
+		"default to day": {
+			cmdOpts:        &commandOptions{},
+			expectedPeriod: template.PeriodDay,
+		},
+		"week": {
+			cmdOpts: &commandOptions{
+				week: true,
+			},
+			expectedPeriod: template.PeriodWeek,
+		},
+		"month": {
+			cmdOpts: &commandOptions{
+				month: true,
+			},
+			expectedPeriod: template.PeriodMonth,
+		},
+		"multiple mutually exclusive flags: week and month set": {
+			cmdOpts: &commandOptions{
+				week:  true,
+				month: true,
+			},
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := setPeriodOpt(test.cmdOpts)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedPeriod, test.cmdOpts.period)
+		})
+	}
+}
+
+func TestValidateNameOpt(t *testing.T) {
+	type testCase struct {
+		cmdOpts   commandOptions
+		shouldErr bool
+	}
+
+	tests := map[string]testCase{
+		"no name": {
+			cmdOpts: commandOptions{
+				date: "2020-04-11",
+			},
+			shouldErr: false,
+		},
+		"name": {
+			cmdOpts: commandOptions{
+				name: "infra-migration",
+			},
+			shouldErr: false,
+		},
+		"name with copy flags": {
+			cmdOpts: commandOptions{
+				name:     "infra-migration",
+				copyDate: "2020-04-11",
+				sections: []string{"TestSection1"},
+			},
+			shouldErr: false,
+		},
+		"name with path separator": {
+			cmdOpts: commandOptions{
+				name: "infra/migration",
+			},
+			shouldErr: true,
+		},
+		"name and date set": {
+			cmdOpts: commandOptions{
+				name: "infra-migration",
+				date: "2020-04-11",
+			},
+			shouldErr: true,
+		},
+		"name and latest set": {
+			cmdOpts: commandOptions{
+				name:   "infra-migration",
+				latest: true,
+			},
+			shouldErr: true,
+		},
+		"name and week set": {
+			cmdOpts: commandOptions{
+				name: "infra-migration",
+				week: true,
+			},
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateNameOpt(test.cmdOpts)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestSetDeleteOpts(t *testing.T) {
 	type testCase struct {
 		cmdOpts                *commandOptions
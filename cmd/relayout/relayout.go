@@ -0,0 +1,111 @@
+package relayout
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/spf13/cobra"
+)
+
+type commandOptions struct {
+	dryRun bool
+}
+
+// move is a relocation of a file from one path to another
+type move struct {
+	from string
+	to   string
+}
+
+// CreateRelayoutCmd creates the relayout subcommand
+func CreateRelayoutCmd(sel *config.Selection) *cobra.Command {
+	cmdOpts := commandOptions{}
+	cmd := &cobra.Command{
+		Use:          "relayout",
+		Short:        "move notes to match the configured layout",
+		Long:         "move dated notes found anywhere in the application directory to the directories specified by the configured layout",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := config.Load(*sel)
+			if err != nil {
+				return err
+			}
+			return run(opts, cmdOpts)
+		},
+	}
+	attachOpts(cmd, &cmdOpts)
+	return cmd
+}
+
+func attachOpts(cmd *cobra.Command, cmdOpts *commandOptions) {
+	flags := cmd.Flags()
+	flags.BoolVar(&cmdOpts.dryRun, "dry-run", false, "print file names to be moved instead of moving files")
+}
+
+func run(templateOpts config.Opts, cmdOpts commandOptions) error {
+	files, err := file.ListFiles(templateOpts.AppDir, -1, templateOpts.File.NotesDir)
+	if err != nil {
+		return err
+	}
+
+	moves := getMoves(files, templateOpts)
+
+	if cmdOpts.dryRun {
+		fmt.Printf("running \"relayout\" will move [%d] files\n", len(moves))
+		for _, m := range moves {
+			fmt.Printf("- %s -> %s\n", m.from, m.to)
+		}
+		return nil
+	}
+
+	numMoved := 0
+	for _, m := range moves {
+		if _, err := os.Stat(m.to); !os.IsNotExist(err) {
+			log.Printf("skipping file [%s]: [%s] already exists", m.from, m.to)
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(m.to), 0o755)
+		if err != nil {
+			log.Printf("unable to move file [%s]: %s", m.from, err)
+			continue
+		}
+		err = os.Rename(m.from, m.to)
+		if err != nil {
+			log.Printf("unable to move file [%s]: %s", m.from, err)
+			continue
+		}
+		file.RemoveEmptyParents(m.from, templateOpts.AppDir)
+		numMoved++
+	}
+	log.Printf("moved [%d] files", numMoved)
+
+	return nil
+}
+
+// getMoves determines the moves required for template files, as paths relative to the application directory,
+// to match the configured layout
+func getMoves(files []string, templateOpts config.Opts) []move {
+	// parse file names without regard to their location
+	flatOpts := templateOpts
+	flatOpts.File.Layout = ""
+
+	moves := []move{}
+	for _, f := range files {
+		period, date, ok := template.ParseTemplateFileName(filepath.Base(f), flatOpts)
+		if !ok {
+			continue
+		}
+		from := filepath.Join(templateOpts.AppDir, f)
+		to := template.NewPeriodTemplate(templateOpts, period, date).GetFilePath()
+		if from == to {
+			continue
+		}
+		moves = append(moves, move{from: from, to: to})
+	}
+	return moves
+}
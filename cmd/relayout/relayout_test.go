@@ -0,0 +1,73 @@
+package relayout
+
+import (
+	"testing"
+
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMoves(t *testing.T) {
+	type testCase struct {
+		layout        string
+		files         []string
+		expectedMoves []move
+	}
+
+	tests := map[string]testCase{
+		"flat to nested layout": {
+			layout: "{{year}}/{{month}}/",
+			files: []string{
+				".config.yml",
+				"archive-Dec2020.txt",
+				"2020-12-20.txt",
+				"2020/12/2020-12-21.txt",
+				"week-2020-12-14.txt",
+			},
+			expectedMoves: []move{
+				{
+					from: "path/to/app/dir/2020-12-20.txt",
+					to:   "path/to/app/dir/2020/12/2020-12-20.txt",
+				},
+				{
+					from: "path/to/app/dir/week-2020-12-14.txt",
+					to:   "path/to/app/dir/2020/12/week-2020-12-14.txt",
+				},
+			},
+		},
+		"nested to flat layout": {
+			layout: "",
+			files: []string{
+				"2020-12-20.txt",
+				"2020/12/2020-12-21.txt",
+				"2020/12/21/month-2020-12.txt",
+			},
+			expectedMoves: []move{
+				{
+					from: "path/to/app/dir/2020/12/2020-12-21.txt",
+					to:   "path/to/app/dir/2020-12-21.txt",
+				},
+				{
+					from: "path/to/app/dir/2020/12/21/month-2020-12.txt",
+					to:   "path/to/app/dir/month-2020-12.txt",
+				},
+			},
+		},
+		"no template files": {
+			layout: "{{year}}/",
+			files: []string{
+				".config.yml",
+				"foobar",
+			},
+			expectedMoves: []move{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			opts.File.Layout = test.layout
+			require.Equal(t, test.expectedMoves, getMoves(test.files, opts))
+		})
+	}
+}
@@ -4,32 +4,54 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dkaslovsky/textnote/cmd/add"
 	"github.com/dkaslovsky/textnote/cmd/archive"
 	"github.com/dkaslovsky/textnote/cmd/config"
+	"github.com/dkaslovsky/textnote/cmd/copy"
 	"github.com/dkaslovsky/textnote/cmd/initialize"
+	"github.com/dkaslovsky/textnote/cmd/notes"
 	"github.com/dkaslovsky/textnote/cmd/open"
+	"github.com/dkaslovsky/textnote/cmd/relayout"
+	"github.com/dkaslovsky/textnote/cmd/unarchive"
 	pkgconf "github.com/dkaslovsky/textnote/pkg/config"
 	"github.com/spf13/cobra"
 )
 
 // Run executes the CLI
 func Run(name string, version string) error {
+	// sel is the notebook and configuration file selected by global flags
+	sel := &pkgconf.Selection{}
+
 	cmd := &cobra.Command{
 		Use:           name,
 		Long:          fmt.Sprintf("Name:\n  %s - a simple tool for creating and organizing daily notes on the command line", name),
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// ensure the application directory and configuration file of the selected notebook exist
+			return pkgconf.InitApp(*sel)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// run the open command with default options as the default application command
-			return open.CreateOpenCmd().Execute()
+			openCmd := open.CreateOpenCmd(sel)
+			openCmd.SetArgs([]string{})
+			return openCmd.Execute()
 		},
 	}
 
+	cmd.PersistentFlags().StringVar(&sel.Notebook, "notebook", "", "name of the notebook to use as registered in the notebook registry (overrides TEXTNOTE_NOTEBOOK)")
+	cmd.PersistentFlags().StringVar(&sel.ConfigFile, "config", "", "path to a configuration file taking precedence over all other configuration files (overrides TEXTNOTE_CONFIG)")
+
 	cmd.AddCommand(
-		open.CreateOpenCmd(),
-		archive.CreateArchiveCmd(),
-		config.CreateConfigCmd(),
-		initialize.CreateInitCmd(),
+		open.CreateOpenCmd(sel),
+		add.CreateAddCmd(sel),
+		archive.CreateArchiveCmd(sel),
+		unarchive.CreateUnarchiveCmd(sel),
+		copy.CreateCopyCmd(sel),
+		notes.CreateNotesCmd(sel),
+		relayout.CreateRelayoutCmd(sel),
+		config.CreateConfigCmd(sel),
+		initialize.CreateInitCmd(sel),
 	)
 
 	setVersion(cmd, version)
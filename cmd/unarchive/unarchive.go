@@ -0,0 +1,138 @@
+package unarchive
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/archive"
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/spf13/cobra"
+)
+
+type commandOptions struct {
+	// mutually exclusive flags for selecting notes to restore
+	date  string
+	month string
+
+	remove bool
+}
+
+// CreateUnarchiveCmd creates the unarchive subcommand
+func CreateUnarchiveCmd(sel *config.Selection) *cobra.Command {
+	cmdOpts := commandOptions{}
+	cmd := &cobra.Command{
+		Use:          "unarchive",
+		Short:        "restore notes from archive files",
+		Long:         "restore individual daily notes from the contents of archive files",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := config.Load(*sel)
+			if err != nil {
+				return err
+			}
+			return run(opts, cmdOpts)
+		},
+	}
+	attachOpts(cmd, &cmdOpts)
+	return cmd
+}
+
+func attachOpts(cmd *cobra.Command, cmdOpts *commandOptions) {
+	flags := cmd.Flags()
+	flags.StringVar(&cmdOpts.date, "date", "", "date of note to restore (cannot be used with month flag)")
+	flags.StringVar(&cmdOpts.month, "month", "", "month of notes to restore, formatted as an archive month (cannot be used with date flag)")
+	flags.BoolVarP(&cmdOpts.remove, "delete", "x", false, "delete restored contents from archive files")
+}
+
+func run(templateOpts config.Opts, cmdOpts commandOptions) error {
+	start, end, err := getDateRange(templateOpts, cmdOpts)
+	if err != nil {
+		return err
+	}
+
+	unarchiver := archive.NewUnarchiver(templateOpts, file.NewReadWriter(), start, end, cmdOpts.remove)
+
+	// archive files are written to the top level of the application directory
+	files, err := file.ListFiles(templateOpts.AppDir, 0)
+	if err != nil {
+		return err
+	}
+
+	// archives separated by section are restored together with the archive of all sections of the same period
+	type archiveKey struct {
+		period      template.Period
+		date        time.Time
+		compression string
+	}
+	restoredArchives := map[archiveKey]struct{}{}
+
+	for _, f := range files {
+		// parse period and date from archive file name, skipping non-archive files
+		period, archiveDate, ok := template.ParseArchiveFileName(f, templateOpts)
+		if !ok {
+			_, period, archiveDate, ok = template.ParseSectionArchiveFileName(f, templateOpts)
+			if !ok {
+				continue
+			}
+		}
+
+		_, compression := file.SplitCompressionExt(f)
+		key := archiveKey{period: period, date: archiveDate, compression: compression}
+		if _, found := restoredArchives[key]; found {
+			continue
+		}
+		restoredArchives[key] = struct{}{}
+
+		err := unarchiver.Restore(period, archiveDate, compression)
+		if err != nil {
+			log.Printf("skipping unrestorable archive file [%s]: %s", f, err)
+			continue
+		}
+	}
+
+	restored := unarchiver.GetRestoredFiles()
+	for _, fileName := range restored {
+		log.Printf("restored file [%s]", fileName)
+	}
+	log.Printf("restored [%d] files from archives", len(restored))
+
+	// delete archive files with all contents restored
+	for _, fileName := range unarchiver.GetEmptyArchiveFiles() {
+		err = os.Remove(fileName)
+		if err != nil {
+			log.Printf("unable to remove empty archive file [%s]: %s", fileName, err)
+			continue
+		}
+		log.Printf("removed empty archive file [%s]", fileName)
+	}
+
+	return nil
+}
+
+// getDateRange returns the range [start, end) of dates of notes to be restored
+func getDateRange(templateOpts config.Opts, cmdOpts commandOptions) (start time.Time, end time.Time, err error) {
+	if cmdOpts.date != "" && cmdOpts.month != "" {
+		return start, end, errors.New("only one of [date, month] flags may be used")
+	}
+	if cmdOpts.date != "" {
+		start, err = time.Parse(templateOpts.Cli.TimeFormat, cmdOpts.date)
+		if err != nil {
+			return start, end, fmt.Errorf("cannot restore note for malformed date [%s]: %w", cmdOpts.date, err)
+		}
+		return start, template.PeriodDay.End(start), nil
+	}
+	if cmdOpts.month != "" {
+		start, err = time.Parse(templateOpts.Archive.MonthTimeFormat, cmdOpts.month)
+		if err != nil {
+			return start, end, fmt.Errorf("cannot restore notes for malformed month [%s]: %w", cmdOpts.month, err)
+		}
+		start = template.PeriodMonth.Start(start)
+		return start, template.PeriodMonth.End(start), nil
+	}
+	return start, end, errors.New("one of [date, month] flags must be used")
+}
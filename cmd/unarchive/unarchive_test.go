@@ -0,0 +1,71 @@
+package unarchive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDateRange(t *testing.T) {
+	type testCase struct {
+		cmdOpts       commandOptions
+		expectedStart time.Time
+		expectedEnd   time.Time
+		shouldErr     bool
+	}
+
+	tests := map[string]testCase{
+		"no flags set": {
+			cmdOpts:   commandOptions{},
+			shouldErr: true,
+		},
+		"multiple mutually exclusive flags: date and month set": {
+			cmdOpts: commandOptions{
+				date:  "2021-01-12",
+				month: "Jan2021",
+			},
+			shouldErr: true,
+		},
+		"malformed date": {
+			cmdOpts: commandOptions{
+				date: "2021Jan12",
+			},
+			shouldErr: true,
+		},
+		"malformed month": {
+			cmdOpts: commandOptions{
+				month: "2021-01",
+			},
+			shouldErr: true,
+		},
+		"date": {
+			cmdOpts: commandOptions{
+				date: "2021-01-12",
+			},
+			expectedStart: time.Date(2021, 1, 12, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2021, 1, 13, 0, 0, 0, 0, time.UTC),
+		},
+		"month": {
+			cmdOpts: commandOptions{
+				month: "Dec2020",
+			},
+			expectedStart: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			start, end, err := getDateRange(templatetest.GetOpts(), test.cmdOpts)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedStart, start)
+			require.Equal(t, test.expectedEnd, end)
+		})
+	}
+}
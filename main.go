@@ -4,7 +4,6 @@ import (
 	"log"
 
 	"github.com/dkaslovsky/textnote/cmd"
-	"github.com/dkaslovsky/textnote/pkg/config"
 )
 
 const name = "textnote"
@@ -14,12 +13,7 @@ var version string // set by build ldflags
 func main() {
 	log.SetFlags(0)
 
-	err := config.InitApp()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = cmd.Run(name, version)
+	err := cmd.Run(name, version)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -3,6 +3,9 @@ package archive
 import (
 	"fmt"
 	"log"
+	"runtime"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/dkaslovsky/textnote/pkg/config"
@@ -12,67 +15,349 @@ import (
 
 // Archiver consolidates templates into archives
 type Archiver struct {
-	opts config.Opts
-	rw   readWriter
-	date time.Time // timestamp for calculating if a file is old enough to be archived
+	opts    config.Opts
+	rw      ReadWriter
+	date    time.Time       // timestamp for calculating if a file is old enough to be archived
+	period  template.Period // period of time consolidated into each archive
+	rebuild bool            // regenerate archives, removing duplicated contents of existing archives
+	workers int             // maximum number of templates read concurrently
 
-	// monthArchives maintains a map of formatted month timestamp to the corresponding archive
-	monthArchives map[string]*template.MonthArchiveTemplate
+	// optional range [start, end) of dates of templates to be archived, replacing the age of templates
+	hasDateRange bool
+	start        time.Time
+	end          time.Time
+
+	// existing maintains the existing archives to be regenerated when archiving their period
+	existing []existingArchive
+	// archives maintains a map of archive key to the corresponding archive
+	archives map[string]*template.ArchiveTemplate
+	// sources maintains a map of archive key to the templates added to the corresponding archive
+	sources map[string][]*template.Template
 	// archivedFiles maintains the file names that have been archived
 	archivedFiles []string
+	// results maintains the outcome of each template considered for archiving
+	results []SourceResult
+	// writtenFiles maintains the file names of archives that have been written
+	writtenFiles []string
 }
 
 // NewArchiver constructs a new Archiver
-func NewArchiver(opts config.Opts, rw readWriter, date time.Time) *Archiver {
+func NewArchiver(opts config.Opts, rw ReadWriter, date time.Time) *Archiver {
+	period, err := template.ParseArchivePeriod(opts.Archive.Period)
+	if err != nil {
+		log.Printf("%s, archiving by month", err)
+	}
+
+	workers := opts.Archive.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
 	return &Archiver{
-		opts: opts,
-		rw:   rw,
-		date: date,
+		opts:    opts,
+		rw:      rw,
+		date:    date,
+		period:  period,
+		workers: workers,
 
-		monthArchives: map[string]*template.MonthArchiveTemplate{},
+		existing:      []existingArchive{},
+		archives:      map[string]*template.ArchiveTemplate{},
+		sources:       map[string][]*template.Template{},
 		archivedFiles: []string{},
+		results:       []SourceResult{},
+		writtenFiles:  []string{},
 	}
 }
 
+// SetDateRange sets the Archiver to archive only templates dated within [start, end) regardless of their age,
+// with a zero start or end leaving the range unbounded on that side
+func (a *Archiver) SetDateRange(start time.Time, end time.Time) {
+	a.hasDateRange = true
+	a.start = start
+	a.end = end
+}
+
 // Add adds a template corresponding to a date to the archive
 func (a *Archiver) Add(date time.Time) error {
-	// recent files are not archived
-	if a.date.Sub(date).Hours() <= float64(a.opts.Archive.AfterDays*24) {
-		return nil
+	return a.addTemplate(a.readTemplate(date))
+}
+
+// Archive archives the templates corresponding to dates, along with the existing archives added to be regenerated,
+// one period at a time so that only the archives of a single period are held in memory. The templates of each period
+// are read concurrently by a bounded pool of workers and added in order of date, and the period's archives are
+// written, if write is set, and verified, if verify is set, before the next period is read. Unarchivable templates
+// are skipped.
+func (a *Archiver) Archive(dates []time.Time, write bool, verify bool) error {
+	for _, group := range a.groupByPeriod(dates) {
+		for _, existing := range group.existing {
+			a.getArchive(existing.date, existing.section)
+		}
+		for _, result := range a.readTemplates(group.dates) {
+			err := a.addTemplate(result)
+			if err != nil {
+				log.Printf("skipping unarchivable file [%s]: %s", result.t.GetFilePath(), err)
+			}
+		}
+		err := a.flush(write, verify)
+		if err != nil {
+			return err
+		}
 	}
+	a.existing = []existingArchive{}
+	return nil
+}
 
+// flush writes and verifies the archives held by the Archiver, as specified, and then releases them
+func (a *Archiver) flush(write bool, verify bool) error {
+	if write {
+		err := a.Write()
+		if err != nil {
+			return err
+		}
+	}
+	if verify {
+		err := a.Verify()
+		if err != nil {
+			return fmt.Errorf("failed to verify archive files: %w", err)
+		}
+	}
+	a.archives = map[string]*template.ArchiveTemplate{}
+	a.sources = map[string][]*template.Template{}
+	return nil
+}
+
+// periodGroup is the dates of templates and the existing archives of a single period
+type periodGroup struct {
+	start    time.Time
+	dates    []time.Time
+	existing []existingArchive
+}
+
+// groupByPeriod groups dates and the existing archives added to the Archiver by the Archiver's period, returning the
+// groups in order of period with the dates of each group sorted
+func (a *Archiver) groupByPeriod(dates []time.Time) []*periodGroup {
+	groups := map[time.Time]*periodGroup{}
+	getGroup := func(date time.Time) *periodGroup {
+		start := a.period.Start(date)
+		group, found := groups[start]
+		if !found {
+			group = &periodGroup{start: start, dates: []time.Time{}, existing: []existingArchive{}}
+			groups[start] = group
+		}
+		return group
+	}
+	for _, date := range dates {
+		group := getGroup(date)
+		group.dates = append(group.dates, date)
+	}
+	for _, existing := range a.existing {
+		group := getGroup(existing.date)
+		group.existing = append(group.existing, existing)
+	}
+
+	sorted := []*periodGroup{}
+	for _, group := range groups {
+		sort.Slice(group.dates, func(i, j int) bool {
+			return group.dates[i].Before(group.dates[j])
+		})
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].start.Before(sorted[j].start)
+	})
+	return sorted
+}
+
+// readResult is the result of reading the template corresponding to a date
+type readResult struct {
+	date     time.Time
+	t        *template.Template
+	selected bool // the template is selected to be archived and was read
+	err      error
+}
+
+// readTemplates reads the templates corresponding to dates concurrently with a bounded pool of workers, returning
+// the results in the order of the dates
+func (a *Archiver) readTemplates(dates []time.Time) []readResult {
+	results := make([]readResult, len(dates))
+	idxs := make(chan int)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < min(a.workers, len(dates)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idxs {
+				results[i] = a.readTemplate(dates[i])
+			}
+		}()
+	}
+	for i := range dates {
+		idxs <- i
+	}
+	close(idxs)
+	wg.Wait()
+
+	return results
+}
+
+// readTemplate reads the template corresponding to a date if it is selected to be archived
+// This function does not modify the Archiver so that it can be called concurrently
+func (a *Archiver) readTemplate(date time.Time) readResult {
 	t := template.NewTemplate(a.opts, date)
-	err := a.rw.Read(t)
-	if err != nil {
-		return fmt.Errorf("cannot add unreadable file [%s] to archive: %w", t.GetFilePath(), err)
+	if !a.isSelected(date) {
+		return readResult{date: date, t: t}
+	}
+	return readResult{date: date, t: t, selected: true, err: a.rw.Read(t)}
+}
+
+// addTemplate adds the contents of a read template to the archive and records the result
+func (a *Archiver) addTemplate(result readResult) error {
+	t, date := result.t, result.date
+
+	if !result.selected {
+		status := StatusSkippedRecent
+		if a.hasDateRange {
+			status = StatusSkippedOutOfRange
+		}
+		a.results = append(a.results, SourceResult{File: t.GetFilePath(), Status: status})
+		return nil
 	}
 
-	monthKey := date.Format(a.opts.Archive.MonthTimeFormat)
-	if _, found := a.monthArchives[monthKey]; !found {
-		a.monthArchives[monthKey] = template.NewMonthArchiveTemplate(a.opts, date)
+	if result.err != nil {
+		err := fmt.Errorf("cannot add unreadable file [%s] to archive: %w", t.GetFilePath(), result.err)
+		a.results = append(a.results, SourceResult{File: t.GetFilePath(), Status: StatusSkippedUnreadable, Error: err.Error()})
+		return err
 	}
 
-	archive := a.monthArchives[monthKey]
+	// contents of each section are archived according to the section's archive policy
+	keys := map[string]struct{}{}
 	for _, section := range a.opts.Section.Names {
+		policy := a.opts.GetSectionPolicy(section)
+		if policy == config.SectionPolicyDrop {
+			continue
+		}
+		archiveSection := ""
+		if policy == config.SectionPolicySeparate {
+			archiveSection = section
+		}
+
+		key, archive := a.getArchive(date, archiveSection)
 		err := archive.ArchiveSectionContents(t, section)
 		if err != nil {
-			return fmt.Errorf("cannot add contents from [%s] to archive: %w", t.GetFilePath(), err)
+			err = fmt.Errorf("cannot add contents from [%s] to archive: %w", t.GetFilePath(), err)
+			a.results = append(a.results, SourceResult{File: t.GetFilePath(), Status: StatusSkippedUnreadable, Error: err.Error()})
+			return err
 		}
+		keys[key] = struct{}{}
 	}
 
+	archiveFiles := []string{}
+	for key := range keys {
+		a.sources[key] = append(a.sources[key], t)
+		archiveFiles = append(archiveFiles, a.archives[key].GetFilePath())
+	}
+	sort.Strings(archiveFiles)
 	a.archivedFiles = append(a.archivedFiles, t.GetFilePath())
+	a.results = append(a.results, SourceResult{File: t.GetFilePath(), Status: StatusAdded, Archives: archiveFiles})
 	return nil
 }
 
+// getArchive returns the archive of all sections, or the archive separated by the specified section, for the
+// period containing a date, creating the archive if it does not exist, along with the key of the archive
+func (a *Archiver) getArchive(date time.Time, section string) (string, *template.ArchiveTemplate) {
+	archive := a.newArchive(date, section)
+	key := getArchiveKey(archive)
+	if existing, found := a.archives[key]; found {
+		return key, existing
+	}
+	a.archives[key] = archive
+	return key, archive
+}
+
+// newArchive constructs the archive of all sections, or the archive separated by the specified section, for the
+// period containing a date
+func (a *Archiver) newArchive(date time.Time, section string) *template.ArchiveTemplate {
+	if section != "" {
+		return template.NewSectionArchiveTemplate(a.opts, a.period, date, section)
+	}
+	return template.NewArchiveTemplate(a.opts, a.period, date)
+}
+
+// getArchivedSections returns the names of the sections that are archived in an archive
+func (a *Archiver) getArchivedSections(archive *template.ArchiveTemplate) []string {
+	if archive.GetSectionName() != "" {
+		return []string{archive.GetSectionName()}
+	}
+	sections := []string{}
+	for _, section := range a.opts.Section.Names {
+		if a.opts.GetSectionPolicy(section) == config.SectionPolicyKeep {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// getArchiveKey returns the key identifying an archive, which is its label for an archive of all sections
+func getArchiveKey(archive *template.ArchiveTemplate) string {
+	if archive.GetSectionName() == "" {
+		return archive.GetLabel()
+	}
+	return archive.GetSectionName() + "/" + archive.GetLabel()
+}
+
+// isSelected evaluates if a template corresponding to a date is to be archived
+func (a *Archiver) isSelected(date time.Time) bool {
+	if a.hasDateRange {
+		if !a.start.IsZero() && date.Before(a.start) {
+			return false
+		}
+		if !a.end.IsZero() && !date.Before(a.end) {
+			return false
+		}
+		return true
+	}
+	// recent files are not archived
+	return a.date.Sub(date).Hours() > float64(a.opts.Archive.AfterDays*24)
+}
+
+// SetRebuild sets the Archiver to regenerate archives, removing all but the last of duplicated contents archived
+// for the same date in existing archive files
+func (a *Archiver) SetRebuild(rebuild bool) {
+	a.rebuild = rebuild
+}
+
+// existingArchive is an existing archive identified by a date within its period and the section it is separated by,
+// which is empty for an archive of all sections
+type existingArchive struct {
+	date    time.Time
+	section string
+}
+
+// AddExisting adds the existing archive of all sections, or the existing archive separated by the specified
+// section, of the Archiver's period containing a date so that it is regenerated when its period is archived even if
+// no templates are added to it
+func (a *Archiver) AddExisting(date time.Time, section string) {
+	a.existing = append(a.existing, existingArchive{date: date, section: section})
+}
+
+// GetPeriod returns the period of time consolidated into each archive
+func (a *Archiver) GetPeriod() template.Period {
+	return a.period
+}
+
 // Write writes all of the archive templates stored in the Archiver
 func (a *Archiver) Write() error {
-	for _, t := range a.monthArchives {
-		if a.rw.Exists(t) {
-			existing := template.NewMonthArchiveTemplate(a.opts, t.GetDate())
+	for _, t := range a.archives {
+		existing, found := a.findExisting(t)
+		if found {
 			err := a.rw.Read(existing)
 			if err != nil {
 				return fmt.Errorf("unable to open existing archive file [%s]: %w", existing.GetFilePath(), err)
 			}
+			if a.rebuild {
+				existing.RemoveDuplicates()
+			}
 			err = t.Merge(existing)
 			if err != nil {
 				return fmt.Errorf("unable to from merge existing archive file [%s] %w", existing.GetFilePath(), err)
@@ -84,18 +369,74 @@ func (a *Archiver) Write() error {
 			return fmt.Errorf("failed to write archive file [%s]: %w", t.GetFilePath(), err)
 		}
 		log.Printf("wrote archive file [%s]", t.GetFilePath())
+		a.writtenFiles = append(a.writtenFiles, t.GetFilePath())
+
+		// remove an existing archive file that was merged into an archive file of a different compression format
+		if found && existing.GetFilePath() != t.GetFilePath() {
+			err := a.rw.Remove(existing)
+			if err != nil {
+				log.Printf("unable to remove merged archive file [%s]: %s", existing.GetFilePath(), err)
+			}
+		}
 	}
 	return nil
 }
 
+// Verify rereads all archive files and confirms that they contain the contents of every template added to the
+// Archiver, returning an error identifying the first missing contents
+func (a *Archiver) Verify() error {
+	for key, t := range a.archives {
+		existing, found := a.findExisting(t)
+		if !found {
+			return fmt.Errorf("archive file [%s] does not exist", t.GetFilePath())
+		}
+		err := a.rw.Read(existing)
+		if err != nil {
+			return fmt.Errorf("unable to reread archive file [%s]: %w", existing.GetFilePath(), err)
+		}
+		sections := a.getArchivedSections(t)
+		for _, src := range a.sources[key] {
+			if !existing.ContainsContents(src, sections) {
+				return fmt.Errorf("archive file [%s] is missing contents of [%s]", existing.GetFilePath(), src.GetFilePath())
+			}
+		}
+	}
+	return nil
+}
+
+// findExisting returns the existing archive file for the same period and sections as an archive, preferring the configured
+// compression format, and an additional bool indicating if an existing file was found
+func (a *Archiver) findExisting(t *template.ArchiveTemplate) (*template.ArchiveTemplate, bool) {
+	compressions := append([]string{t.GetCompression()}, file.Compressions...)
+	for _, compression := range compressions {
+		existing := a.newArchive(t.GetDate(), t.GetSectionName())
+		existing.SetCompression(compression)
+		if a.rw.Exists(existing) {
+			return existing, true
+		}
+	}
+	return nil, false
+}
+
 // GetArchivedFiles returns the files that have been archived
 func (a *Archiver) GetArchivedFiles() []string {
 	return a.archivedFiles
 }
 
-// readWriter is the interface for executing file operations
-type readWriter interface {
+// GetResults returns the outcome of each template considered for archiving
+func (a *Archiver) GetResults() []SourceResult {
+	return a.results
+}
+
+// GetWrittenFiles returns the archive files that have been written
+func (a *Archiver) GetWrittenFiles() []string {
+	return a.writtenFiles
+}
+
+// ReadWriter is the interface for executing file operations
+type ReadWriter interface {
 	Read(file.ReadWriteable) error
 	Overwrite(file.ReadWriteable) error
 	Exists(file.ReadWriteable) bool
+	Remove(file.ReadWriteable) error
 }
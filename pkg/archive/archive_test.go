@@ -2,7 +2,11 @@ package archive
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -19,9 +23,13 @@ import (
 //
 
 type testReadWriter struct {
-	exists  bool
-	toRead  string
-	written string
+	exists        bool
+	existingFiles []string // restricts exists to the listed files if set
+	toRead        string
+	files         map[string]string // contents read from specific files in place of toRead
+	readErr       error
+	written       string
+	removed       []string
 }
 
 func newTestReadWriter(exists bool, toRead string) *testReadWriter {
@@ -29,12 +37,19 @@ func newTestReadWriter(exists bool, toRead string) *testReadWriter {
 		exists:  exists,
 		toRead:  toRead,
 		written: "",
+		removed: []string{},
 	}
 }
 
 func (trw *testReadWriter) Read(rwable file.ReadWriteable) error {
-	r := strings.NewReader(trw.toRead)
-	return rwable.Load(r)
+	if trw.readErr != nil {
+		return trw.readErr
+	}
+	toRead, found := trw.files[rwable.GetFilePath()]
+	if !found {
+		toRead = trw.toRead
+	}
+	return rwable.Load(strings.NewReader(toRead))
 }
 
 func (trw *testReadWriter) Overwrite(rwable file.ReadWriteable) error {
@@ -48,9 +63,17 @@ func (trw *testReadWriter) Overwrite(rwable file.ReadWriteable) error {
 }
 
 func (trw *testReadWriter) Exists(rwable file.ReadWriteable) bool {
+	if len(trw.existingFiles) > 0 {
+		return trw.exists && slices.Contains(trw.existingFiles, rwable.GetFilePath())
+	}
 	return trw.exists
 }
 
+func (trw *testReadWriter) Remove(rwable file.ReadWriteable) error {
+	trw.removed = append(trw.removed, rwable.GetFilePath())
+	return nil
+}
+
 //
 // Tests
 //
@@ -394,16 +417,16 @@ _p_TestSection3_q_
 				err = m.Load(strings.NewReader(text))
 				require.NoError(t, err)
 
-				a.monthArchives[key] = m
+				a.archives[key] = m
 			}
 
 			err := a.Add(test.date)
 			require.NoError(t, err)
 
-			require.Equal(t, len(test.expectedArchives), len(a.monthArchives))
+			require.Equal(t, len(test.expectedArchives), len(a.archives))
 			for key, expectedText := range test.expectedArchives {
 				buf := new(bytes.Buffer)
-				monthArchive, found := a.monthArchives[key]
+				monthArchive, found := a.archives[key]
 				require.True(t, found)
 				err := monthArchive.Write(buf)
 				require.NoError(t, err)
@@ -420,6 +443,391 @@ _p_TestSection3_q_
 	}
 }
 
+func TestAddWithDateRange(t *testing.T) {
+	type testCase struct {
+		start    time.Time
+		end      time.Time
+		date     time.Time
+		expected bool
+	}
+
+	now := templatetest.Date
+
+	tests := map[string]testCase{
+		"recent date within range": {
+			start:    time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			date:     time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		"date on start of range": {
+			start:    time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			date:     time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		"date before range": {
+			start:    time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			date:     time.Date(2020, 11, 30, 0, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		"date on end of range": {
+			start:    time.Date(2020, 11, 1, 0, 0, 0, 0, time.UTC),
+			end:      time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			date:     time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		"date before range unbounded at start": {
+			end:      time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			date:     time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		"date after range unbounded at end": {
+			start:    time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			date:     time.Date(2020, 12, 20, 0, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			a := NewArchiver(opts, newTestReadWriter(true, ""), now)
+			a.SetDateRange(test.start, test.end)
+
+			err := a.Add(test.date)
+			require.NoError(t, err)
+			if test.expected {
+				require.Equal(t, []string{template.NewTemplate(opts, test.date).GetFilePath()}, a.GetArchivedFiles())
+			} else {
+				require.Empty(t, a.GetArchivedFiles())
+			}
+		})
+	}
+}
+
+func TestArchive(t *testing.T) {
+	opts := templatetest.GetOpts()
+	opts.AppDir = t.TempDir()
+	rw := file.NewReadWriter()
+
+	dates := []time.Time{
+		time.Date(2020, 12, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 10, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 11, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC), // too recent to be archived
+	}
+	for _, date := range dates {
+		tmpl := template.NewTemplate(opts, date)
+		require.NoError(t, tmpl.AppendSectionContents("TestSection1", date.Format("text 2006-01-02")))
+		require.NoError(t, rw.Overwrite(tmpl))
+	}
+
+	a := NewArchiver(opts, rw, templatetest.Date)
+	a.workers = 2
+	err := a.Archive(dates, true, true)
+	require.NoError(t, err)
+
+	// archives are written one period at a time and released after writing
+	require.Equal(t, []string{
+		filepath.Join(opts.AppDir, "archive-Oct2020.txt"),
+		filepath.Join(opts.AppDir, "archive-Nov2020.txt"),
+		filepath.Join(opts.AppDir, "archive-Dec2020.txt"),
+	}, a.GetWrittenFiles())
+	require.Empty(t, a.archives)
+	require.Empty(t, a.sources)
+
+	require.Equal(t, []string{
+		filepath.Join(opts.AppDir, "2020-10-31.txt"),
+		filepath.Join(opts.AppDir, "2020-11-15.txt"),
+		filepath.Join(opts.AppDir, "2020-12-01.txt"),
+		filepath.Join(opts.AppDir, "2020-12-02.txt"),
+	}, a.GetArchivedFiles())
+	require.Len(t, a.GetResults(), len(dates))
+
+	for _, date := range dates[:4] {
+		archived, found, err := ReadArchived(opts, rw, date)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, template.NewTemplate(opts, date).GetFilePath(), archived.GetFilePath())
+	}
+}
+
+func TestNewArchiverWorkers(t *testing.T) {
+	type testCase struct {
+		workers  int
+		expected int
+	}
+
+	tests := map[string]testCase{
+		"configured workers": {
+			workers:  3,
+			expected: 3,
+		},
+		"number of CPUs if not configured": {
+			workers:  0,
+			expected: runtime.NumCPU(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			opts.Archive.Workers = test.workers
+			a := NewArchiver(opts, newTestReadWriter(true, ""), templatetest.Date)
+			require.Equal(t, test.expected, a.workers)
+		})
+	}
+}
+
+func TestArchiveRebuildByPeriod(t *testing.T) {
+	opts := templatetest.GetOpts()
+	opts.AppDir = t.TempDir()
+	rw := file.NewReadWriter()
+
+	existing := []time.Time{
+		time.Date(2020, 12, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC),
+	}
+	for _, date := range existing {
+		archive := template.NewArchiveTemplate(opts, template.PeriodMonth, date)
+		require.NoError(t, rw.Overwrite(archive))
+	}
+	date := time.Date(2020, 11, 15, 0, 0, 0, 0, time.UTC)
+	tmpl := template.NewTemplate(opts, date)
+	require.NoError(t, tmpl.AppendSectionContents("TestSection1", "text"))
+	require.NoError(t, rw.Overwrite(tmpl))
+
+	a := NewArchiver(opts, rw, templatetest.Date)
+	a.SetRebuild(true)
+	for _, date := range existing {
+		a.AddExisting(date, "")
+	}
+	err := a.Archive([]time.Time{date}, true, true)
+	require.NoError(t, err)
+
+	// existing archives are regenerated with the templates of their period
+	require.Equal(t, []string{
+		filepath.Join(opts.AppDir, "archive-Oct2020.txt"),
+		filepath.Join(opts.AppDir, "archive-Nov2020.txt"),
+		filepath.Join(opts.AppDir, "archive-Dec2020.txt"),
+	}, a.GetWrittenFiles())
+	require.Empty(t, a.archives)
+}
+
+func TestGetResults(t *testing.T) {
+	opts := templatetest.GetOpts()
+	recent := time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC)
+	old := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("results by age", func(t *testing.T) {
+		a := NewArchiver(opts, newTestReadWriter(true, ""), templatetest.Date)
+		require.NoError(t, a.Add(recent))
+		require.NoError(t, a.Add(old))
+		require.Equal(t, []SourceResult{
+			{
+				File:   filepath.Join(opts.AppDir, "2020-12-19.txt"),
+				Status: StatusSkippedRecent,
+			},
+			{
+				File:     filepath.Join(opts.AppDir, "2020-12-01.txt"),
+				Status:   StatusAdded,
+				Archives: []string{filepath.Join(opts.AppDir, "archive-Dec2020.txt")},
+			},
+		}, a.GetResults())
+	})
+
+	t.Run("results by date range", func(t *testing.T) {
+		a := NewArchiver(opts, newTestReadWriter(true, ""), templatetest.Date)
+		a.SetDateRange(recent, time.Time{})
+		require.NoError(t, a.Add(recent))
+		require.NoError(t, a.Add(old))
+		require.Equal(t, []SourceResult{
+			{
+				File:     filepath.Join(opts.AppDir, "2020-12-19.txt"),
+				Status:   StatusAdded,
+				Archives: []string{filepath.Join(opts.AppDir, "archive-Dec2020.txt")},
+			},
+			{
+				File:   filepath.Join(opts.AppDir, "2020-12-01.txt"),
+				Status: StatusSkippedOutOfRange,
+			},
+		}, a.GetResults())
+	})
+
+	t.Run("results of unreadable file", func(t *testing.T) {
+		trw := newTestReadWriter(true, "")
+		trw.readErr = errors.New("read failed")
+		a := NewArchiver(opts, trw, templatetest.Date)
+		require.Error(t, a.Add(old))
+		results := a.GetResults()
+		require.Len(t, results, 1)
+		require.Equal(t, StatusSkippedUnreadable, results[0].Status)
+		require.NotEmpty(t, results[0].Error)
+		require.Empty(t, a.GetArchivedFiles())
+	})
+}
+
+func TestAddWithSectionPolicies(t *testing.T) {
+	templateText := `-^-[Sun] 13 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+
+
+
+_p_TestSection2_q_
+text2
+
+
+
+_p_TestSection3_q_
+text3
+
+
+
+`
+
+	opts := templatetest.GetOpts()
+	opts.Archive.SectionPolicies = map[string]string{
+		"TestSection2": "drop",
+		"TestSection3": "separate",
+	}
+	date := time.Date(2020, 12, 13, 0, 0, 0, 0, time.UTC)
+
+	a := NewArchiver(opts, newTestReadWriter(true, templateText), templatetest.Date)
+	err := a.Add(date)
+	require.NoError(t, err)
+
+	require.Len(t, a.archives, 2)
+	archive, found := a.archives["Dec2020"]
+	require.True(t, found)
+	require.Equal(t, filepath.Join(opts.AppDir, "archive-Dec2020.txt"), archive.GetFilePath())
+	buf := new(bytes.Buffer)
+	require.NoError(t, archive.Write(buf))
+	require.Equal(t, `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-13]
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`, buf.String())
+
+	archive, found = a.archives["TestSection3/Dec2020"]
+	require.True(t, found)
+	require.Equal(t, filepath.Join(opts.AppDir, "archive-testsection3-Dec2020.txt"), archive.GetFilePath())
+	buf = new(bytes.Buffer)
+	require.NoError(t, archive.Write(buf))
+	require.Equal(t, `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection3_q_
+[2020-12-13]
+text3
+
+
+
+`, buf.String())
+
+	require.Len(t, a.sources["Dec2020"], 1)
+	require.Len(t, a.sources["TestSection3/Dec2020"], 1)
+	require.Equal(t, []string{filepath.Join(opts.AppDir, "2020-12-13.txt")}, a.GetArchivedFiles())
+}
+
+func TestAddByPeriod(t *testing.T) {
+	templateText := `-^-[Mon] 07 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	type testCase struct {
+		period      string
+		expectedKey string
+	}
+
+	tests := map[string]testCase{
+		"week": {
+			period:      "week",
+			expectedKey: "2020-W50",
+		},
+		"month": {
+			period:      "month",
+			expectedKey: "Dec2020",
+		},
+		"quarter": {
+			period:      "quarter",
+			expectedKey: "2020-Q4",
+		},
+		"year": {
+			period:      "year",
+			expectedKey: "2020",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			opts.Archive.Period = test.period
+			a := NewArchiver(opts, newTestReadWriter(true, templateText), templatetest.Date)
+
+			err := a.Add(time.Date(2020, 12, 7, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+			err = a.Add(time.Date(2020, 12, 8, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+
+			require.Len(t, a.archives, 1)
+			archive, found := a.archives[test.expectedKey]
+			require.True(t, found)
+
+			buf := new(bytes.Buffer)
+			err = archive.Write(buf)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf(`ARCHIVEPREFIX %s ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-07]
+text1
+[2020-12-08]
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`, test.expectedKey), buf.String())
+		})
+	}
+}
+
 func TestWrite(t *testing.T) {
 	type testCase struct {
 		text         string
@@ -603,6 +1011,68 @@ existingText3b
 
 
 
+`,
+		},
+		"write to existing archive replacing contents for the same date": {
+			exists: true,
+			text: `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-15]
+newText1a
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			existingText: `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-15]
+existingText1a
+[2020-12-15]
+existingText1a
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+[2020-12-15]
+existingText3a
+[2020-12-22]
+existingText3b
+
+
+
+`,
+			expected: `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-15]
+newText1a
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+[2020-12-22]
+existingText3b
+
+
+
 `,
 		},
 	}
@@ -619,7 +1089,7 @@ existingText3b
 
 			trw := newTestReadWriter(test.exists, test.existingText)
 			a := NewArchiver(opts, trw, date)
-			a.monthArchives[key] = template
+			a.archives[key] = template
 
 			err = a.Write()
 			require.NoError(t, err)
@@ -627,3 +1097,269 @@ existingText3b
 		})
 	}
 }
+
+func TestWriteCompressed(t *testing.T) {
+	existingText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-15]
+existingText1a
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	type testCase struct {
+		existingFile    string
+		expectedFile    string
+		expectedRemoved []string
+		expectedWritten string
+	}
+
+	opts := templatetest.GetOpts()
+	opts.Archive.Compress = "gzip"
+
+	tests := map[string]testCase{
+		"merge existing archive with configured compression": {
+			existingFile:    filepath.Join(opts.AppDir, "archive-Dec2020.txt.gz"),
+			expectedFile:    filepath.Join(opts.AppDir, "archive-Dec2020.txt.gz"),
+			expectedRemoved: []string{},
+			expectedWritten: existingText,
+		},
+		"merge and remove existing uncompressed archive": {
+			existingFile:    filepath.Join(opts.AppDir, "archive-Dec2020.txt"),
+			expectedFile:    filepath.Join(opts.AppDir, "archive-Dec2020.txt.gz"),
+			expectedRemoved: []string{filepath.Join(opts.AppDir, "archive-Dec2020.txt")},
+			expectedWritten: existingText,
+		},
+		"merge and remove existing archive with different compression": {
+			existingFile:    filepath.Join(opts.AppDir, "archive-Dec2020.txt.zst"),
+			expectedFile:    filepath.Join(opts.AppDir, "archive-Dec2020.txt.gz"),
+			expectedRemoved: []string{filepath.Join(opts.AppDir, "archive-Dec2020.txt.zst")},
+			expectedWritten: existingText,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			date := templatetest.Date
+			archive := template.NewMonthArchiveTemplate(opts, date)
+			require.Equal(t, test.expectedFile, archive.GetFilePath())
+
+			trw := newTestReadWriter(true, existingText)
+			trw.existingFiles = []string{test.existingFile}
+			a := NewArchiver(opts, trw, date)
+			a.archives[archive.GetLabel()] = archive
+
+			err := a.Write()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedWritten, trw.written)
+			require.Equal(t, test.expectedRemoved, trw.removed)
+		})
+	}
+}
+
+func TestWriteRebuild(t *testing.T) {
+	existingText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-15]
+existingText1a
+[2020-12-16]
+existingText1b
+[2020-12-15]
+existingText1c
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	expected := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-15]
+existingText1c
+[2020-12-16]
+existingText1b
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	opts := templatetest.GetOpts()
+	date := templatetest.Date
+
+	t.Run("rebuild existing archive", func(t *testing.T) {
+		trw := newTestReadWriter(true, existingText)
+		a := NewArchiver(opts, trw, date)
+		a.SetRebuild(true)
+		a.AddExisting(date, "")
+
+		err := a.Archive([]time.Time{}, true, false)
+		require.NoError(t, err)
+		require.Equal(t, expected, trw.written)
+	})
+
+	t.Run("existing archive is not rebuilt without rebuild set", func(t *testing.T) {
+		trw := newTestReadWriter(true, existingText)
+		a := NewArchiver(opts, trw, date)
+		a.AddExisting(date, "")
+
+		err := a.Archive([]time.Time{}, true, false)
+		require.NoError(t, err)
+		require.NotEqual(t, expected, trw.written)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	type testCase struct {
+		archiveText string
+		exists      bool
+		shouldErr   bool
+	}
+
+	sourceText := `-^-[Fri] 18 Dec 2020-v-
+
+_p_TestSection1_q_
+text1a
+
+text1b
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+text3
+
+
+
+`
+
+	tests := map[string]testCase{
+		"archive contains source": {
+			archiveText: `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-17]
+other
+[2020-12-18]
+text1a
+text1b
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+[2020-12-18]
+text3
+
+
+
+`,
+			exists: true,
+		},
+		"archive missing section contents of source": {
+			archiveText: `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1a
+text1b
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			exists:    true,
+			shouldErr: true,
+		},
+		"archive contains source contents for a different date": {
+			archiveText: `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-17]
+text1a
+text1b
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+[2020-12-17]
+text3
+
+
+
+`,
+			exists:    true,
+			shouldErr: true,
+		},
+		"archive does not exist": {
+			exists:    false,
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			date := time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC)
+
+			src := template.NewTemplate(opts, date)
+			err := src.Load(strings.NewReader(sourceText))
+			require.NoError(t, err)
+			archive := template.NewMonthArchiveTemplate(opts, date)
+
+			trw := newTestReadWriter(test.exists, test.archiveText)
+			a := NewArchiver(opts, trw, date)
+			a.archives[archive.GetLabel()] = archive
+			a.sources[archive.GetLabel()] = []*template.Template{src}
+
+			err = a.Verify()
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
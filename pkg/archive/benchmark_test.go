@@ -0,0 +1,154 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const benchmarkYears = 10
+
+var benchmarkNow = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// setupBenchmark writes daily notes for a number of years to a temporary application directory and returns the
+// configuration and dates of the notes
+func setupBenchmark(b *testing.B, years int) (config.Opts, []time.Time) {
+	b.Helper()
+
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	opts := templatetest.GetOpts()
+	opts.AppDir = b.TempDir()
+	rw := file.NewReadWriter()
+
+	dates := []time.Time{}
+	for date := benchmarkNow.AddDate(-years, 0, 0); date.Before(benchmarkNow); date = date.AddDate(0, 0, 1) {
+		t := template.NewTemplate(opts, date)
+		for i, section := range opts.Section.Names {
+			text := strings.Repeat(fmt.Sprintf("- item %d of %s\n", i, date.Format("2006-01-02")), 10)
+			require.NoError(b, t.AppendSectionContents(section, text))
+		}
+		require.NoError(b, rw.Overwrite(t))
+		dates = append(dates, date)
+	}
+	return opts, dates
+}
+
+// removeArchives removes the archive files written to an application directory by a benchmark iteration
+func removeArchives(b *testing.B, opts config.Opts) {
+	b.Helper()
+
+	archives, err := filepath.Glob(filepath.Join(opts.AppDir, opts.Archive.FilePrefix+"*"))
+	require.NoError(b, err)
+	for _, archive := range archives {
+		require.NoError(b, os.Remove(archive))
+	}
+}
+
+// samplePeakHeap samples the allocated heap until the returned function is called, which returns the peak sample
+func samplePeakHeap() func() uint64 {
+	runtime.GC()
+	stop := make(chan struct{})
+	peak := make(chan uint64)
+	go func() {
+		stats := runtime.MemStats{}
+		peakAlloc := uint64(0)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > peakAlloc {
+				peakAlloc = stats.HeapAlloc
+			}
+			select {
+			case <-stop:
+				peak <- peakAlloc
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() uint64 {
+		close(stop)
+		return <-peak
+	}
+}
+
+// BenchmarkArchive benchmarks archiving the same notes with the baseline approach, reading templates sequentially and
+// holding all archives in memory until written, and with templates read concurrently by a bounded pool of workers
+// and archives written one period at a time
+func BenchmarkArchive(b *testing.B) {
+	opts, dates := setupBenchmark(b, benchmarkYears)
+
+	b.Run("baseline", func(b *testing.B) {
+		benchmarkArchive(b, opts, func(a *Archiver) error {
+			for _, date := range dates {
+				err := a.Add(date)
+				if err != nil {
+					return err
+				}
+			}
+			return a.Write()
+		})
+	})
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			benchmarkArchive(b, opts, func(a *Archiver) error {
+				a.workers = workers
+				return a.Archive(dates, true, false)
+			})
+		})
+	}
+}
+
+// benchmarkArchive benchmarks a function archiving notes with a new Archiver, reporting the peak allocated heap
+func benchmarkArchive(b *testing.B, opts config.Opts, archive func(*Archiver) error) {
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	peak := uint64(0)
+	for i := 0; i < b.N; i++ {
+		stopSampling := samplePeakHeap()
+		require.NoError(b, archive(NewArchiver(opts, file.NewReadWriter(), benchmarkNow)))
+
+		b.StopTimer()
+		peak = max(peak, stopSampling())
+		removeArchives(b, opts)
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
+
+// BenchmarkArchiveTemplateWrite benchmarks writing a large archive
+func BenchmarkArchiveTemplateWrite(b *testing.B) {
+	opts := templatetest.GetOpts()
+	archive := template.NewArchiveTemplate(opts, template.PeriodYear, benchmarkNow)
+	for date := benchmarkNow; date.Year() == benchmarkNow.Year(); date = date.AddDate(0, 0, 1) {
+		t := template.NewTemplate(opts, date)
+		for _, section := range opts.Section.Names {
+			require.NoError(b, t.AppendSectionContents(section, strings.Repeat("- item\n", 10)))
+			require.NoError(b, archive.ArchiveSectionContents(t, section))
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		require.NoError(b, archive.Write(io.Discard))
+	}
+}
@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+const (
+	// StatusAdded is the status of a source file added to archives before the archives are written, which is replaced
+	// by the final status of the source file once the outcome of the archive run is known
+	StatusAdded = "added"
+	// StatusArchived is the status of a source file added to archives that were written
+	StatusArchived = "archived"
+	// StatusNotWritten is the status of a source file added to archives that were not written as specified
+	StatusNotWritten = "not-written"
+	// StatusDryRun is the status of a source file that would be added to archives by a dry-run
+	StatusDryRun = "dry-run"
+	// StatusRolledBack is the status of a source file added to archives that were rolled back after a failure
+	StatusRolledBack = "rolled-back"
+	// StatusSkippedRecent is the status of a source file skipped because it is too recent to be archived
+	StatusSkippedRecent = "skipped-recent"
+	// StatusSkippedOutOfRange is the status of a source file skipped because it is dated outside of the selected range
+	StatusSkippedOutOfRange = "skipped-out-of-range"
+	// StatusSkippedUnreadable is the status of a source file skipped because it could not be read
+	StatusSkippedUnreadable = "skipped-unreadable"
+)
+
+// Report is a machine-readable record of the outcome of an archive run
+type Report struct {
+	Sources  []SourceResult   `json:"sources"`
+	Archives []ArchiveResult  `json:"archives"`
+	Deleted  []DeletionResult `json:"deleted"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// SourceResult records the outcome of considering a source file for archiving
+type SourceResult struct {
+	File     string   `json:"file"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+	Archives []string `json:"archives,omitempty"` // archive files to which the source file's contents are added
+}
+
+// ArchiveResult records an archive file written by an archive run
+type ArchiveResult struct {
+	File  string `json:"file"`
+	Bytes int64  `json:"bytes"`
+}
+
+// DeletionResult records the outcome of deleting an archived source file
+type DeletionResult struct {
+	File    string `json:"file"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewReport constructs a new empty Report
+func NewReport() *Report {
+	return &Report{
+		Sources:  []SourceResult{},
+		Archives: []ArchiveResult{},
+		Deleted:  []DeletionResult{},
+	}
+}
+
+// SetAddedStatus sets the final status of the source files added to archives
+func (r *Report) SetAddedStatus(status string) {
+	for i := range r.Sources {
+		if r.Sources[i].Status == StatusAdded {
+			r.Sources[i].Status = status
+		}
+	}
+}
+
+// Write writes the Report as JSON with its entries sorted by file name
+func (r *Report) Write(w io.Writer) error {
+	sort.SliceStable(r.Sources, func(i, j int) bool {
+		return r.Sources[i].File < r.Sources[j].File
+	})
+	sort.SliceStable(r.Archives, func(i, j int) bool {
+		return r.Archives[i].File < r.Archives[j].File
+	})
+	sort.SliceStable(r.Deleted, func(i, j int) bool {
+		return r.Deleted[i].File < r.Deleted[j].File
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,87 @@
+package archive
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportWrite(t *testing.T) {
+	t.Run("write empty report", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := NewReport().Write(buf)
+		require.NoError(t, err)
+		require.Equal(t, `{
+  "sources": [],
+  "archives": [],
+  "deleted": []
+}
+`, buf.String())
+	})
+
+	t.Run("write report sorted by file name", func(t *testing.T) {
+		report := NewReport()
+		report.Sources = []SourceResult{
+			{File: "2020-12-02.txt", Status: StatusSkippedUnreadable, Error: "unreadable"},
+			{File: "2020-12-01.txt", Status: StatusArchived, Archives: []string{"archive-Dec2020.txt"}},
+		}
+		report.Archives = []ArchiveResult{
+			{File: "archive-Dec2020.txt", Bytes: 100},
+		}
+		report.Deleted = []DeletionResult{
+			{File: "2020-12-01.txt", Deleted: true},
+		}
+		report.Error = "failed"
+
+		buf := new(bytes.Buffer)
+		err := report.Write(buf)
+		require.NoError(t, err)
+		require.Equal(t, `{
+  "sources": [
+    {
+      "file": "2020-12-01.txt",
+      "status": "archived",
+      "archives": [
+        "archive-Dec2020.txt"
+      ]
+    },
+    {
+      "file": "2020-12-02.txt",
+      "status": "skipped-unreadable",
+      "error": "unreadable"
+    }
+  ],
+  "archives": [
+    {
+      "file": "archive-Dec2020.txt",
+      "bytes": 100
+    }
+  ],
+  "deleted": [
+    {
+      "file": "2020-12-01.txt",
+      "deleted": true
+    }
+  ],
+  "error": "failed"
+}
+`, buf.String())
+	})
+}
+
+func TestSetAddedStatus(t *testing.T) {
+	report := NewReport()
+	report.Sources = []SourceResult{
+		{File: "2020-12-01.txt", Status: StatusAdded, Archives: []string{"archive-Dec2020.txt"}},
+		{File: "2020-12-02.txt", Status: StatusSkippedUnreadable, Error: "unreadable"},
+		{File: "2020-12-19.txt", Status: StatusSkippedRecent},
+	}
+
+	report.SetAddedStatus(StatusRolledBack)
+	require.Equal(t, []SourceResult{
+		{File: "2020-12-01.txt", Status: StatusRolledBack, Archives: []string{"archive-Dec2020.txt"}},
+		{File: "2020-12-02.txt", Status: StatusSkippedUnreadable, Error: "unreadable"},
+		{File: "2020-12-19.txt", Status: StatusSkippedRecent},
+	}, report.Sources)
+}
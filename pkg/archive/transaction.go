@@ -0,0 +1,276 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dkaslovsky/textnote/pkg/file"
+)
+
+const (
+	// journalDirName is the name of the directory in the application directory holding the journal and backups
+	journalDirName = ".archive-journal"
+	// journalFileName is the name of the journal file in the journal directory
+	journalFileName = "journal.json"
+
+	// StateWriting is the state of a transaction writing archive files
+	StateWriting = "writing"
+	// StateRemoving is the state of a transaction removing files that have been verified as archived
+	StateRemoving = "removing"
+)
+
+// Transaction executes the file operations of an archive run, recording every change to an existing file in a
+// journal in the application directory so that an interrupted run can be resumed or rolled back
+type Transaction struct {
+	rw      *file.ReadWriter
+	dir     string
+	journal journal
+}
+
+type journal struct {
+	State   string         `json:"state"`
+	Entries []journalEntry `json:"entries"`
+	Pending []string       `json:"pending"` // files to be removed after archives are verified
+}
+
+// journalEntry records a file that was changed by a transaction, with an empty backup indicating a created file
+type journalEntry struct {
+	File   string `json:"file"`
+	Backup string `json:"backup,omitempty"`
+}
+
+// BeginTransaction starts a new Transaction in an application directory, failing if the journal of an interrupted
+// transaction exists
+func BeginTransaction(appDir string) (*Transaction, error) {
+	tx := newTransaction(appDir)
+	if _, err := os.Stat(tx.dir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("journal of an interrupted archive run exists in [%s]", tx.dir)
+	}
+	err := os.MkdirAll(tx.dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create archive journal: %w", err)
+	}
+	tx.journal.State = StateWriting
+	return tx, tx.save()
+}
+
+// OpenTransaction opens the Transaction of an interrupted archive run in an application directory, returning an
+// additional bool indicating if an interrupted Transaction was found
+func OpenTransaction(appDir string) (*Transaction, bool, error) {
+	tx := newTransaction(appDir)
+	raw, err := os.ReadFile(filepath.Join(tx.dir, journalFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		if _, err := os.Stat(tx.dir); err == nil {
+			// the journal directory was created but no changes were recorded
+			return tx, true, nil
+		}
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to read archive journal: %w", err)
+	}
+	err = json.Unmarshal(raw, &tx.journal)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to parse archive journal: %w", err)
+	}
+	return tx, true, nil
+}
+
+func newTransaction(appDir string) *Transaction {
+	return &Transaction{
+		rw:  file.NewReadWriter(),
+		dir: filepath.Join(appDir, journalDirName),
+		journal: journal{
+			Entries: []journalEntry{},
+			Pending: []string{},
+		},
+	}
+}
+
+// GetState returns the state of the Transaction
+func (tx *Transaction) GetState() string {
+	return tx.journal.State
+}
+
+// Read reads from file
+func (tx *Transaction) Read(rwable file.ReadWriteable) error {
+	return tx.rw.Read(rwable)
+}
+
+// Exists evaluates if a file exists
+func (tx *Transaction) Exists(rwable file.ReadWriteable) bool {
+	return tx.rw.Exists(rwable)
+}
+
+// Overwrite backs up an existing file, or records that the file is created, before writing a template to the file.
+// A backup is copied before it is recorded so that a recorded backup is always complete.
+func (tx *Transaction) Overwrite(rwable file.ReadWriteable) error {
+	fileName := rwable.GetFilePath()
+	if !tx.isRecorded(fileName) {
+		entry := journalEntry{File: fileName}
+		if tx.rw.Exists(rwable) {
+			entry.Backup = tx.getBackupPath(fileName)
+			err := copyFile(fileName, entry.Backup)
+			if err != nil {
+				return fmt.Errorf("unable to back up file [%s]: %w", fileName, err)
+			}
+		}
+		err := tx.record(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.rw.Overwrite(rwable)
+}
+
+// Remove removes a file by moving it to a backup
+func (tx *Transaction) Remove(rwable file.ReadWriteable) error {
+	return tx.RemoveFile(rwable.GetFilePath())
+}
+
+// RemoveFile removes a file by moving it to a backup
+func (tx *Transaction) RemoveFile(fileName string) error {
+	entry := journalEntry{
+		File:   fileName,
+		Backup: tx.getBackupPath(fileName),
+	}
+	// record before moving so that a file is never removed without a record of its backup
+	err := tx.record(entry)
+	if err != nil {
+		return err
+	}
+	return os.Rename(fileName, entry.Backup)
+}
+
+// SetPending records the files to be removed after archives are verified and sets the Transaction to the
+// removing state
+func (tx *Transaction) SetPending(fileNames []string) error {
+	tx.journal.State = StateRemoving
+	tx.journal.Pending = fileNames
+	return tx.save()
+}
+
+// GetPending returns the files to be removed after archives are verified
+func (tx *Transaction) GetPending() []string {
+	return tx.journal.Pending
+}
+
+// RemovePending removes the pending files of the Transaction that have not yet been removed, returning the names
+// of the removed files
+func (tx *Transaction) RemovePending() ([]string, error) {
+	removed := []string{}
+	for _, fileName := range tx.journal.Pending {
+		if _, err := os.Stat(fileName); os.IsNotExist(err) {
+			continue
+		}
+		err := tx.RemoveFile(fileName)
+		if err != nil {
+			return removed, fmt.Errorf("unable to remove file [%s]: %w", fileName, err)
+		}
+		removed = append(removed, fileName)
+	}
+	return removed, nil
+}
+
+// Commit completes the Transaction by discarding the journal and all backups
+func (tx *Transaction) Commit() error {
+	return os.RemoveAll(tx.dir)
+}
+
+// Rollback reverts all changes recorded by the Transaction, in reverse order, and discards the journal
+func (tx *Transaction) Rollback() error {
+	for i := len(tx.journal.Entries) - 1; i >= 0; i-- {
+		entry := tx.journal.Entries[i]
+
+		if entry.Backup == "" {
+			err := os.Remove(entry.File)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("unable to remove file [%s] created by archive run: %w", entry.File, err)
+			}
+			continue
+		}
+
+		if _, err := os.Stat(entry.Backup); os.IsNotExist(err) {
+			// the file was recorded but not yet backed up
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(entry.File), 0o755)
+		if err != nil {
+			return fmt.Errorf("unable to restore file [%s]: %w", entry.File, err)
+		}
+		err = os.Rename(entry.Backup, entry.File)
+		if err != nil {
+			return fmt.Errorf("unable to restore file [%s]: %w", entry.File, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (tx *Transaction) isRecorded(fileName string) bool {
+	for _, entry := range tx.journal.Entries {
+		if entry.File == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+// record appends an entry to the journal and persists the journal
+func (tx *Transaction) record(entry journalEntry) error {
+	tx.journal.Entries = append(tx.journal.Entries, entry)
+	return tx.save()
+}
+
+// save persists the journal to disk, replacing the previous journal atomically
+func (tx *Transaction) save() error {
+	raw, err := json.MarshalIndent(tx.journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to write archive journal: %w", err)
+	}
+	tmpName := filepath.Join(tx.dir, journalFileName+".tmp")
+	f, err := os.Create(tmpName)
+	if err != nil {
+		return fmt.Errorf("unable to write archive journal: %w", err)
+	}
+	_, err = f.Write(raw)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write archive journal: %w", err)
+	}
+	return os.Rename(tmpName, filepath.Join(tx.dir, journalFileName))
+}
+
+// getBackupPath returns a unique path in the journal directory for the backup of a file
+func (tx *Transaction) getBackupPath(fileName string) string {
+	return filepath.Join(tx.dir, fmt.Sprintf("%d-%s", len(tx.journal.Entries), filepath.Base(fileName)))
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
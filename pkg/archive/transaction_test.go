@@ -0,0 +1,141 @@
+package archive
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//
+// mocks
+//
+
+type testFile struct {
+	path     string
+	contents string
+}
+
+func (tf *testFile) Load(r io.Reader) error {
+	raw, err := io.ReadAll(r)
+	tf.contents = string(raw)
+	return err
+}
+
+func (tf *testFile) Write(w io.Writer) error {
+	_, err := w.Write([]byte(tf.contents))
+	return err
+}
+
+func (tf *testFile) GetFilePath() string {
+	return tf.path
+}
+
+//
+// Tests
+//
+
+func TestTransaction(t *testing.T) {
+	setup := func(t *testing.T) (string, *testFile, *testFile, *testFile) {
+		dir := t.TempDir()
+		existing := &testFile{path: filepath.Join(dir, "existing.txt"), contents: "existing"}
+		source := &testFile{path: filepath.Join(dir, "sub", "source.txt"), contents: "source"}
+		created := &testFile{path: filepath.Join(dir, "created.txt"), contents: "created"}
+		for _, f := range []*testFile{existing, source} {
+			require.NoError(t, os.MkdirAll(filepath.Dir(f.path), 0o755))
+			require.NoError(t, os.WriteFile(f.path, []byte(f.contents), 0o644))
+		}
+		return dir, existing, source, created
+	}
+
+	requireContents := func(t *testing.T, path string, expected string) {
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(raw))
+	}
+
+	run := func(t *testing.T, dir string, existing *testFile, source *testFile, created *testFile) {
+		tx, err := BeginTransaction(dir)
+		require.NoError(t, err)
+
+		existing.contents = "updated"
+		require.NoError(t, tx.Overwrite(existing))
+		require.NoError(t, tx.Overwrite(created))
+		require.NoError(t, tx.SetPending([]string{source.path}))
+		removed, err := tx.RemovePending()
+		require.NoError(t, err)
+		require.Equal(t, []string{source.path}, removed)
+	}
+
+	t.Run("begin transaction fails with interrupted transaction", func(t *testing.T) {
+		dir, _, _, _ := setup(t)
+		_, err := BeginTransaction(dir)
+		require.NoError(t, err)
+		_, err = BeginTransaction(dir)
+		require.Error(t, err)
+	})
+
+	t.Run("open transaction without interrupted transaction", func(t *testing.T) {
+		dir, _, _, _ := setup(t)
+		_, found, err := OpenTransaction(dir)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		dir, existing, source, created := setup(t)
+		run(t, dir, existing, source, created)
+
+		tx, found, err := OpenTransaction(dir)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, StateRemoving, tx.GetState())
+		require.NoError(t, tx.Commit())
+
+		requireContents(t, existing.path, "updated")
+		requireContents(t, created.path, "created")
+		require.NoFileExists(t, source.path)
+		require.NoDirExists(t, filepath.Join(dir, journalDirName))
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		dir, existing, source, created := setup(t)
+		run(t, dir, existing, source, created)
+
+		tx, found, err := OpenTransaction(dir)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.NoError(t, tx.Rollback())
+
+		requireContents(t, existing.path, "existing")
+		requireContents(t, source.path, "source")
+		require.NoFileExists(t, created.path)
+		require.NoDirExists(t, filepath.Join(dir, journalDirName))
+	})
+
+	t.Run("resume removing pending files", func(t *testing.T) {
+		dir, existing, source, _ := setup(t)
+		other := &testFile{path: filepath.Join(dir, "other.txt"), contents: "other"}
+		require.NoError(t, os.WriteFile(other.path, []byte(other.contents), 0o644))
+
+		tx, err := BeginTransaction(dir)
+		require.NoError(t, err)
+		require.NoError(t, tx.Overwrite(existing))
+		require.NoError(t, tx.SetPending([]string{source.path, other.path}))
+		// simulate an interruption after removing the first pending file
+		require.NoError(t, tx.RemoveFile(source.path))
+
+		tx, found, err := OpenTransaction(dir)
+		require.NoError(t, err)
+		require.True(t, found)
+		removed, err := tx.RemovePending()
+		require.NoError(t, err)
+		require.Equal(t, []string{other.path}, removed)
+		require.NoError(t, tx.Commit())
+
+		require.NoFileExists(t, source.path)
+		require.NoFileExists(t, other.path)
+	})
+}
@@ -0,0 +1,201 @@
+package archive
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/file"
+	"github.com/dkaslovsky/textnote/pkg/template"
+)
+
+// Unarchiver restores notes from archives
+type Unarchiver struct {
+	opts   config.Opts
+	rw     ReadWriter
+	start  time.Time // first date of notes to be restored
+	end    time.Time // first date after the notes to be restored
+	remove bool      // remove restored contents from archives
+
+	// restoredFiles maintains the file names of notes that have been restored
+	restoredFiles []string
+	// emptyArchiveFiles maintains the file names of archives left empty after removing restored contents
+	emptyArchiveFiles []string
+}
+
+// NewUnarchiver constructs a new Unarchiver for restoring notes dated within [start, end)
+func NewUnarchiver(opts config.Opts, rw ReadWriter, start time.Time, end time.Time, remove bool) *Unarchiver {
+	return &Unarchiver{
+		opts:   opts,
+		rw:     rw,
+		start:  start,
+		end:    end,
+		remove: remove,
+
+		restoredFiles:     []string{},
+		emptyArchiveFiles: []string{},
+	}
+}
+
+// Restore restores notes from the archive files of a compression format for a period containing the specified
+// date, combining the contents of the archive of all sections with those of archives separated by section
+func (u *Unarchiver) Restore(period template.Period, date time.Time, compression string) error {
+	// skip archives that cannot contain notes to be restored
+	if !period.Start(date).Before(u.end) || !u.start.Before(period.End(date)) {
+		return nil
+	}
+
+	archives, err := readArchives(u.opts, u.rw, period, date, compression)
+	if err != nil {
+		return err
+	}
+
+	numRestored := 0
+	for _, noteDate := range getArchivedDates(archives) {
+		if noteDate.Before(u.start) || !noteDate.Before(u.end) {
+			continue
+		}
+
+		t := template.NewTemplate(u.opts, noteDate)
+		for _, archive := range archives {
+			archive.ExtractDateInto(t)
+		}
+		if u.rw.Exists(t) {
+			log.Printf("skipping restore of existing file [%s]", t.GetFilePath())
+			continue
+		}
+		err := u.rw.Overwrite(t)
+		if err != nil {
+			return fmt.Errorf("failed to write restored file [%s]: %w", t.GetFilePath(), err)
+		}
+		u.restoredFiles = append(u.restoredFiles, t.GetFilePath())
+		numRestored++
+
+		if u.remove {
+			for _, archive := range archives {
+				archive.RemoveDate(noteDate)
+			}
+		}
+	}
+
+	if !u.remove || numRestored == 0 {
+		return nil
+	}
+	for _, archive := range archives {
+		if archive.IsEmpty() {
+			u.emptyArchiveFiles = append(u.emptyArchiveFiles, archive.GetFilePath())
+			continue
+		}
+		err = u.rw.Overwrite(archive)
+		if err != nil {
+			return fmt.Errorf("failed to write archive file [%s]: %w", archive.GetFilePath(), err)
+		}
+	}
+	return nil
+}
+
+// GetRestoredFiles returns the files that have been restored
+func (u *Unarchiver) GetRestoredFiles() []string {
+	return u.restoredFiles
+}
+
+// GetEmptyArchiveFiles returns the archive files left without contents after removing restored contents
+func (u *Unarchiver) GetEmptyArchiveFiles() []string {
+	return u.emptyArchiveFiles
+}
+
+// ReadArchived constructs a new Template for a date populated with the contents archived for that date, returning
+// an additional bool indicating if archived contents were found. Archive files of the configured period and
+// compression format are searched first and contents are read from the first archive file containing the date.
+func ReadArchived(opts config.Opts, rw ReadWriter, date time.Time) (*template.Template, bool, error) {
+	configured, err := template.ParseArchivePeriod(opts.Archive.Period)
+	if err != nil {
+		configured = template.PeriodMonth
+	}
+	archivePeriods := append([]template.Period{configured}, template.GetArchivePeriods()...)
+	compressions := append([]string{opts.Archive.Compress}, file.Compressions...)
+
+	for _, period := range archivePeriods {
+		for _, compression := range compressions {
+			archives, err := readArchives(opts, rw, period, date, compression)
+			if err != nil {
+				return nil, false, err
+			}
+			t := template.NewTemplate(opts, date)
+			for _, archive := range archives {
+				archive.ExtractDateInto(t)
+			}
+			if !t.IsEmpty() {
+				return t, true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// ReadArchivedNote returns a Template populated with the contents archived for the date of a daily note, or the note
+// itself if it is a weekly, monthly, or named note or no contents are archived for its date, along with an additional
+// bool indicating if archived contents were found
+func ReadArchivedNote(opts config.Opts, rw ReadWriter, t *template.Template) (*template.Template, bool, error) {
+	if t.GetPeriod() != template.PeriodDay || t.GetName() != "" {
+		return t, false, nil
+	}
+	archived, found, err := ReadArchived(opts, rw, t.GetDate())
+	if err != nil {
+		return t, false, fmt.Errorf("cannot read archived note: %w", err)
+	}
+	if !found {
+		return t, false, nil
+	}
+	return archived, true, nil
+}
+
+// readArchives reads the existing archive of all sections and archives separated by section of a compression
+// format for the period containing a date
+func readArchives(
+	opts config.Opts,
+	rw ReadWriter,
+	period template.Period,
+	date time.Time,
+	compression string,
+) ([]*template.ArchiveTemplate, error) {
+	candidates := []*template.ArchiveTemplate{template.NewArchiveTemplate(opts, period, date)}
+	for _, section := range opts.Section.Names {
+		candidates = append(candidates, template.NewSectionArchiveTemplate(opts, period, date, section))
+	}
+
+	archives := []*template.ArchiveTemplate{}
+	for _, archive := range candidates {
+		archive.SetCompression(compression)
+		if !rw.Exists(archive) {
+			continue
+		}
+		err := rw.Read(archive)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open archive file [%s]: %w", archive.GetFilePath(), err)
+		}
+		archives = append(archives, archive)
+	}
+	return archives, nil
+}
+
+// getArchivedDates returns the sorted unique dates of the contents of archives
+func getArchivedDates(archives []*template.ArchiveTemplate) []time.Time {
+	dates := []time.Time{}
+	seen := map[time.Time]struct{}{}
+	for _, archive := range archives {
+		for _, date := range archive.GetDates() {
+			if _, found := seen[date]; found {
+				continue
+			}
+			seen[date] = struct{}{}
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return dates
+}
@@ -0,0 +1,352 @@
+package archive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/template"
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestore(t *testing.T) {
+	archiveText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1
+[2020-12-19]
+text2
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	type testCase struct {
+		start              time.Time
+		end                time.Time
+		remove             bool
+		existingNotes      []string
+		expectedWritten    string
+		expectedRestored   []string
+		expectedEmptyFiles []string
+	}
+
+	opts := templatetest.GetOpts()
+
+	tests := map[string]testCase{
+		"range outside of archive": {
+			start:              time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:                time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+			expectedWritten:    "",
+			expectedRestored:   []string{},
+			expectedEmptyFiles: []string{},
+		},
+		"range without archived notes": {
+			start:              time.Date(2020, 12, 20, 0, 0, 0, 0, time.UTC),
+			end:                time.Date(2020, 12, 21, 0, 0, 0, 0, time.UTC),
+			expectedWritten:    "",
+			expectedRestored:   []string{},
+			expectedEmptyFiles: []string{},
+		},
+		"restore single date": {
+			start: time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2020, 12, 20, 0, 0, 0, 0, time.UTC),
+			expectedWritten: `-^-[Sat] 19 Dec 2020-v-
+
+_p_TestSection1_q_
+text2
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			expectedRestored:   []string{filepath.Join(opts.AppDir, "2020-12-19.txt")},
+			expectedEmptyFiles: []string{},
+		},
+		"restore single date and remove from archive": {
+			start:  time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2020, 12, 20, 0, 0, 0, 0, time.UTC),
+			remove: true,
+			expectedWritten: `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			expectedRestored:   []string{filepath.Join(opts.AppDir, "2020-12-19.txt")},
+			expectedEmptyFiles: []string{},
+		},
+		"restore all dates and remove from archive": {
+			start:  time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			remove: true,
+			expectedWritten: `-^-[Sat] 19 Dec 2020-v-
+
+_p_TestSection1_q_
+text2
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			expectedRestored: []string{
+				filepath.Join(opts.AppDir, "2020-12-18.txt"),
+				filepath.Join(opts.AppDir, "2020-12-19.txt"),
+			},
+			expectedEmptyFiles: []string{filepath.Join(opts.AppDir, "archive-Dec2020.txt")},
+		},
+		"existing notes are not overwritten": {
+			start:  time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			remove: true,
+			existingNotes: []string{
+				filepath.Join(opts.AppDir, "2020-12-18.txt"),
+				filepath.Join(opts.AppDir, "2020-12-19.txt"),
+			},
+			expectedWritten:    "",
+			expectedRestored:   []string{},
+			expectedEmptyFiles: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rw := newTestReadWriter(true, archiveText)
+			rw.existingFiles = append([]string{filepath.Join(opts.AppDir, "archive-Dec2020.txt")}, test.existingNotes...)
+			u := NewUnarchiver(opts, rw, test.start, test.end, test.remove)
+
+			err := u.Restore(template.PeriodMonth, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), "")
+			require.NoError(t, err)
+			require.Equal(t, test.expectedWritten, rw.written)
+			require.Equal(t, test.expectedRestored, u.GetRestoredFiles())
+			require.Equal(t, test.expectedEmptyFiles, u.GetEmptyArchiveFiles())
+		})
+	}
+}
+
+func TestRestoreSectionArchives(t *testing.T) {
+	archiveText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+	sectionArchiveText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection3_q_
+[2020-12-18]
+text3
+
+
+
+`
+
+	opts := templatetest.GetOpts()
+	archiveFile := filepath.Join(opts.AppDir, "archive-Dec2020.txt")
+	sectionArchiveFile := filepath.Join(opts.AppDir, "archive-testsection3-Dec2020.txt")
+
+	rw := newTestReadWriter(true, "")
+	rw.existingFiles = []string{archiveFile, sectionArchiveFile}
+	rw.files = map[string]string{
+		archiveFile:        archiveText,
+		sectionArchiveFile: sectionArchiveText,
+	}
+	u := NewUnarchiver(opts, rw, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), true)
+
+	err := u.Restore(template.PeriodMonth, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), "")
+	require.NoError(t, err)
+	require.Equal(t, `-^-[Fri] 18 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+text3
+
+
+`, rw.written)
+	require.Equal(t, []string{filepath.Join(opts.AppDir, "2020-12-18.txt")}, u.GetRestoredFiles())
+	require.Equal(t, []string{archiveFile, sectionArchiveFile}, u.GetEmptyArchiveFiles())
+}
+
+func TestReadArchived(t *testing.T) {
+	archiveText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	type testCase struct {
+		date          time.Time
+		existingFile  string
+		expectedFound bool
+	}
+
+	opts := templatetest.GetOpts()
+	opts.Archive.Compress = "zstd"
+
+	tests := map[string]testCase{
+		"date in archive of configured period and compression": {
+			date:          time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC),
+			existingFile:  filepath.Join(opts.AppDir, "archive-Dec2020.txt.zst"),
+			expectedFound: true,
+		},
+		"date in uncompressed archive": {
+			date:          time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC),
+			existingFile:  filepath.Join(opts.AppDir, "archive-Dec2020.txt"),
+			expectedFound: true,
+		},
+		"date not in existing archive": {
+			date:          time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC),
+			existingFile:  filepath.Join(opts.AppDir, "archive-Dec2020.txt"),
+			expectedFound: false,
+		},
+		"no existing archive": {
+			date:          time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC),
+			existingFile:  filepath.Join(opts.AppDir, "archive-Nov2020.txt"),
+			expectedFound: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rw := newTestReadWriter(true, archiveText)
+			rw.existingFiles = []string{test.existingFile}
+
+			archived, found, err := ReadArchived(opts, rw, test.date)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedFound, found)
+			if !test.expectedFound {
+				return
+			}
+			require.Equal(t, filepath.Join(opts.AppDir, "2020-12-18.txt"), archived.GetFilePath())
+			require.False(t, archived.IsEmpty())
+		})
+	}
+}
+
+func TestReadArchivedNote(t *testing.T) {
+	archiveText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	type testCase struct {
+		note          *template.Template
+		expectedFound bool
+	}
+
+	opts := templatetest.GetOpts()
+	date := time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]testCase{
+		"daily note with archived contents": {
+			note:          template.NewTemplate(opts, date),
+			expectedFound: true,
+		},
+		"daily note without archived contents": {
+			note:          template.NewTemplate(opts, date.AddDate(0, 0, 1)),
+			expectedFound: false,
+		},
+		"weekly note": {
+			note:          template.NewPeriodTemplate(opts, template.PeriodWeek, date),
+			expectedFound: false,
+		},
+		"named note": {
+			note:          template.NewNamedTemplate(opts, "ideas"),
+			expectedFound: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rw := newTestReadWriter(true, archiveText)
+			rw.existingFiles = []string{filepath.Join(opts.AppDir, "archive-Dec2020.txt")}
+
+			note, found, err := ReadArchivedNote(opts, rw, test.note)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedFound, found)
+			if !test.expectedFound {
+				require.Same(t, test.note, note)
+				return
+			}
+			require.Equal(t, test.note.GetFilePath(), note.GetFilePath())
+			require.False(t, note.IsEmpty())
+		})
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"dario.cat/mergo"
+	"github.com/dkaslovsky/textnote/pkg/editor"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -16,21 +19,22 @@ import (
 const (
 	// envAppDir is the name of the environment variable specifying the application directory
 	envAppDir = "TEXTNOTE_DIR"
-	// fileName is the name of the configuration file
+	// fileName is the name of the configuration file in an application directory
 	fileName = ".config.yml"
 )
 
-// appDir is the directory in which the application stores its files
-var appDir = os.Getenv(envAppDir)
-
 // Opts are options that configure the application
 type Opts struct {
-	AppDir                  string      `yaml:"-"` // AppDir is always read from the environment and is not written to file
+	AppDir                  string      `yaml:"-"`       // AppDir is always resolved from the environment or notebook registry and is not written to file
+	Version                 int         `yaml:"version"` // Version is the version of the configuration file, upgraded when loaded
 	Header                  HeaderOpts  `yaml:"header"`
 	Section                 SectionOpts `yaml:"section"`
 	File                    FileOpts    `yaml:"file"`
 	Archive                 ArchiveOpts `yaml:"archive"`
 	Cli                     CliOpts     `yaml:"cli"`
+	Periods                 PeriodsOpts `yaml:"periods"`
+	Add                     AddOpts     `yaml:"add"`
+	Editor                  EditorOpts  `yaml:"editor"`
 	TemplateFileCountThresh int         `yaml:"templateFileCountThresh" env:"TEXTNOTE_TEMPLATE_FILE_COUNT_THRESH" env-description:"threshold for warning too many template files"`
 }
 
@@ -48,25 +52,134 @@ type SectionOpts struct {
 	Suffix           string   `yaml:"suffix" env:"TEXTNOTE_SECTION_SUFFIX" env-description:"suffix to attach to section names"`
 	TrailingNewlines int      `yaml:"trailingNewlines" env:"TEXTNOTE_SECTION_TRAILING_NEWLINES" env-description:"number of newlines to attach to end of each section"`
 	Names            []string `yaml:"names" env:"TEXTNOTE_SECTION_NAMES" env-description:"section names"`
+	// Items configure sections individually and, when set, take precedence over the section names
+	Items []SectionItem `yaml:"items"`
+}
+
+// SectionItem is the configuration of a single section of a note
+type SectionItem struct {
+	Name             string   `yaml:"name"`
+	Aliases          []string `yaml:"aliases,omitempty"`          // previous names of the section recognized when reading notes and selecting sections
+	DefaultContent   string   `yaml:"defaultContent,omitempty"`   // content of the section when a note is created
+	TrailingNewlines int      `yaml:"trailingNewlines,omitempty"` // overrides section.trailingNewlines if nonzero
+	CarryForward     string   `yaml:"carryForward,omitempty"`     // copy or move the section's contents from the previous note when a daily note is created
+	ArchivePolicy    string   `yaml:"archivePolicy,omitempty"`    // overrides the section's archive.sectionPolicies entry if set
+	Cursor           bool     `yaml:"cursor,omitempty"`           // place the cursor at the section's contents when opening a note
+}
+
+const (
+	// CarryForwardCopy copies the contents of a section from the previous note when a note is created
+	CarryForwardCopy = "copy"
+	// CarryForwardMove moves the contents of a section from the previous note when a note is created
+	CarryForwardMove = "move"
+)
+
+// GetItem returns the configuration of a section configured as an item and an additional bool indicating if the
+// section is configured as an item
+func (o SectionOpts) GetItem(name string) (SectionItem, bool) {
+	for _, item := range o.Items {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return SectionItem{}, false
+}
+
+// GetTrailingNewlines returns the number of newlines to attach to the end of a section
+func (o SectionOpts) GetTrailingNewlines(name string) int {
+	if item, found := o.GetItem(name); found && item.TrailingNewlines > 0 {
+		return item.TrailingNewlines
+	}
+	return o.TrailingNewlines
+}
+
+// GetCarryForwardSections returns the names of the sections with contents copied and moved from the previous note
+// when a note is created
+func (o SectionOpts) GetCarryForwardSections() (copied []string, moved []string) {
+	for _, item := range o.Items {
+		switch item.CarryForward {
+		case CarryForwardCopy:
+			copied = append(copied, item.Name)
+		case CarryForwardMove:
+			moved = append(moved, item.Name)
+		}
+	}
+	return copied, moved
 }
 
 // FileOpts are options for configuring file outputs
 type FileOpts struct {
-	Ext        string `yaml:"ext" env:"TEXTNOTE_FILE_EXT" env-description:"extension for all files written"`
-	TimeFormat string `yaml:"timeFormat" env:"TEXTNOTE_FILE_TIME_FORMAT" env-description:"formatting string to form file names from timestamps"`
-	CursorLine int    `yaml:"cursorLine" env:"TEXTNOTE_FILE_CURSOR_LINE" env-description:"line to place cursor when opening"`
+	Ext            string `yaml:"ext" env:"TEXTNOTE_FILE_EXT" env-description:"extension for all files written"`
+	TimeFormat     string `yaml:"timeFormat" env:"TEXTNOTE_FILE_TIME_FORMAT" env-description:"formatting string to form file names from timestamps"`
+	CursorLine     int    `yaml:"cursorLine" env:"TEXTNOTE_FILE_CURSOR_LINE" env-description:"line to place cursor when opening"`
+	CursorSection  string `yaml:"cursorSection" env:"TEXTNOTE_FILE_CURSOR_SECTION" env-description:"section at which to place cursor when opening, overriding the cursor line (no section if empty)"`
+	CursorPosition string `yaml:"cursorPosition" env:"TEXTNOTE_FILE_CURSOR_POSITION" env-description:"position of cursor in the cursor section (start or end)"`
+	NotesDir       string `yaml:"notesDir" env:"TEXTNOTE_FILE_NOTES_DIR" env-description:"subdirectory of the application directory for named notes"`
+	Layout         string `yaml:"layout" env:"TEXTNOTE_FILE_LAYOUT" env-description:"subdirectory layout for dated notes using {{year}}, {{month}}, and {{day}} placeholders"`
+}
+
+const (
+	// CursorPositionStart places the cursor at the first line of the contents of the cursor section
+	CursorPositionStart = "start"
+	// CursorPositionEnd places the cursor after the last line of the contents of the cursor section
+	CursorPositionEnd = "end"
+)
+
+// GetCursorSection returns the name of the section at which to place the cursor when opening a note, as configured by
+// the cursor section or otherwise by a section item, which is empty if no section is configured for the cursor
+func (o Opts) GetCursorSection() string {
+	if o.File.CursorSection != "" {
+		return o.File.CursorSection
+	}
+	for _, item := range o.Section.Items {
+		if item.Cursor {
+			return item.Name
+		}
+	}
+	return ""
 }
 
 // ArchiveOpts are options for configuring note archives
 type ArchiveOpts struct {
-	AfterDays                int    `yaml:"afterDays" env:"TEXTNOTE_ARCHIVE_AFTER_DAYS" env-description:"number of days after which to archive a file"`
-	FilePrefix               string `yaml:"filePrefix" env:"TEXTNOTE_ARCHIVE_FILE_PREFIX" env-description:"prefix attached to the file name of all archive files"`
-	HeaderPrefix             string `yaml:"headerPrefix" env:"TEXTNOTE_ARCHIVE_HEADER_PREFIX" env-description:"override header prefix for archive files"`
-	HeaderSuffix             string `yaml:"headerSuffix" env:"TEXTNOTE_ARCHIVE_HEADER_SUFFIX" env-description:"override header suffix for archive files"`
-	SectionContentPrefix     string `yaml:"sectionContentPrefix" env:"TEXTNOTE_ARCHIVE_SECTION_CONTENT_PREFIX" env-description:"prefix to attach to section content date"`
-	SectionContentSuffix     string `yaml:"sectionContentSuffix" env:"TEXTNOTE_ARCHIVE_SECTION_CONTENT_SUFFIX" env-description:"suffix to attach to section content date"`
-	SectionContentTimeFormat string `yaml:"sectionContentTimeFormat" env:"TEXTNOTE_ARCHIVE_SECTION_CONTENT_TIME_FORMAT" env-description:"formatting string dated section content"`
-	MonthTimeFormat          string `yaml:"monthTimeFormat" env:"TEXTNOTE_ARCHIVE_MONTH_TIME_FORMAT" env-description:"formatting string for month archive timestamps"`
+	AfterDays                int               `yaml:"afterDays" env:"TEXTNOTE_ARCHIVE_AFTER_DAYS" env-description:"number of days after which to archive a file"`
+	FilePrefix               string            `yaml:"filePrefix" env:"TEXTNOTE_ARCHIVE_FILE_PREFIX" env-description:"prefix attached to the file name of all archive files"`
+	HeaderPrefix             string            `yaml:"headerPrefix" env:"TEXTNOTE_ARCHIVE_HEADER_PREFIX" env-description:"override header prefix for archive files"`
+	HeaderSuffix             string            `yaml:"headerSuffix" env:"TEXTNOTE_ARCHIVE_HEADER_SUFFIX" env-description:"override header suffix for archive files"`
+	SectionContentPrefix     string            `yaml:"sectionContentPrefix" env:"TEXTNOTE_ARCHIVE_SECTION_CONTENT_PREFIX" env-description:"prefix to attach to section content date"`
+	SectionContentSuffix     string            `yaml:"sectionContentSuffix" env:"TEXTNOTE_ARCHIVE_SECTION_CONTENT_SUFFIX" env-description:"suffix to attach to section content date"`
+	SectionContentTimeFormat string            `yaml:"sectionContentTimeFormat" env:"TEXTNOTE_ARCHIVE_SECTION_CONTENT_TIME_FORMAT" env-description:"formatting string dated section content"`
+	MonthTimeFormat          string            `yaml:"monthTimeFormat" env:"TEXTNOTE_ARCHIVE_MONTH_TIME_FORMAT" env-description:"formatting string for month archive timestamps"`
+	Period                   string            `yaml:"period" env:"TEXTNOTE_ARCHIVE_PERIOD" env-description:"period of time consolidated into each archive file (week, month, quarter, or year)"`
+	Compress                 string            `yaml:"compress" env:"TEXTNOTE_ARCHIVE_COMPRESS" env-description:"compression format of archive files (gzip or zstd, uncompressed if empty)"`
+	SectionPolicies          map[string]string `yaml:"sectionPolicies" env:"TEXTNOTE_ARCHIVE_SECTION_POLICIES" env-description:"archive policy of each section (keep, drop, or separate), formatted as section:policy pairs separated by commas"`
+	TableOfContents          bool              `yaml:"tableOfContents" env:"TEXTNOTE_ARCHIVE_TABLE_OF_CONTENTS" env-description:"write a table of contents of archived dates after the header of archive files"`
+	Workers                  int               `yaml:"workers" env:"TEXTNOTE_ARCHIVE_WORKERS" env-description:"maximum number of notes read concurrently when archiving (number of CPUs if 0)"`
+}
+
+const (
+	// SectionPolicyKeep archives the contents of a section in the archive file for all sections
+	SectionPolicyKeep = "keep"
+	// SectionPolicyDrop discards the contents of a section when archiving
+	SectionPolicyDrop = "drop"
+	// SectionPolicySeparate archives the contents of a section in a separate archive file for the section
+	SectionPolicySeparate = "separate"
+)
+
+// GetSectionPolicy returns the archive policy of a section, as configured by the section's item or otherwise by the
+// archive section policies
+func (o Opts) GetSectionPolicy(section string) string {
+	if item, found := o.Section.GetItem(section); found && item.ArchivePolicy != "" {
+		return item.ArchivePolicy
+	}
+	return o.Archive.GetSectionPolicy(section)
+}
+
+// GetSectionPolicy returns the archive policy of a section, defaulting to keeping the section's contents
+func (o ArchiveOpts) GetSectionPolicy(section string) string {
+	if policy, found := o.SectionPolicies[section]; found {
+		return policy
+	}
+	return SectionPolicyKeep
 }
 
 // CliOpts are options for configuring the CLI
@@ -74,15 +187,56 @@ type CliOpts struct {
 	TimeFormat string `yaml:"timeFormat" env:"TEXTNOTE_CLI_TIME_FORMAT" env-description:"formatting string for timestamp CLI flags"`
 }
 
-// OptsBackCompat are options maintained for backwards compatibility that will be honored in the absence (zero-value) of their
-// replacements as handled in loadBackCompat()
-type OptsBackCompat struct {
-	// TemplateFileCountThresh holds the value of the field "templateFileCountTresh" (note the typo) in a yaml configuration file
-	TemplateFileCountThresh int `yaml:"templateFileCountTresh"`
+// layoutPlaceholders are the placeholders supported in the layout for dated notes
+var layoutPlaceholders = map[string]struct{}{
+	"year":  {},
+	"month": {},
+	"day":   {},
+}
+
+// archivePeriods are the periods of time by which notes can be archived
+var archivePeriods = map[string]struct{}{
+	"week":    {},
+	"month":   {},
+	"quarter": {},
+	"year":    {},
+}
+
+// archiveCompressions are the compression formats of archive files
+var archiveCompressions = map[string]struct{}{
+	"":     {},
+	"gzip": {},
+	"zstd": {},
+}
+
+var layoutPlaceholderRegex = regexp.MustCompile(`\{\{(\w*)\}\}`)
+
+// AddOpts are options for configuring text added to notes from the command line
+type AddOpts struct {
+	TimestampFormat string `yaml:"timestampFormat" env:"TEXTNOTE_ADD_TIMESTAMP_FORMAT" env-description:"formatting string for timestamp prefixed to added text (no prefix if empty)"`
+}
+
+// EditorOpts are options for configuring the editor for opening notes
+type EditorOpts struct {
+	Command string `yaml:"command" env:"TEXTNOTE_EDITOR_COMMAND" env-description:"command template for opening notes with {file} and {line} placeholders, such as \"code --wait --goto {file}:{line}\" (uses EDITOR if empty)"`
+}
+
+// PeriodsOpts are options for configuring notes that span a period longer than a day
+type PeriodsOpts struct {
+	Weekly  PeriodOpts `yaml:"weekly" env-prefix:"TEXTNOTE_PERIODS_WEEKLY_"`
+	Monthly PeriodOpts `yaml:"monthly" env-prefix:"TEXTNOTE_PERIODS_MONTHLY_"`
+}
+
+// PeriodOpts are options for configuring the notes of a single period
+type PeriodOpts struct {
+	FileTimeFormat   string   `yaml:"fileTimeFormat" env:"FILE_TIME_FORMAT" env-description:"formatting string to form file names from the first day of the period"`
+	HeaderTimeFormat string   `yaml:"headerTimeFormat" env:"HEADER_TIME_FORMAT" env-description:"formatting string to form headers from the first day of the period"`
+	Sections         []string `yaml:"sections" env:"SECTIONS" env-description:"section names"`
 }
 
 func getDefaultOpts() Opts {
 	return Opts{
+		Version: currentVersion,
 		Header: HeaderOpts{
 			Prefix:           "",
 			Suffix:           "",
@@ -100,9 +254,13 @@ func getDefaultOpts() Opts {
 			},
 		},
 		File: FileOpts{
-			Ext:        "txt",
-			TimeFormat: "2006-01-02",
-			CursorLine: 4,
+			Ext:            "txt",
+			TimeFormat:     "2006-01-02",
+			CursorLine:     4,
+			CursorSection:  "",
+			CursorPosition: CursorPositionStart,
+			NotesDir:       "notes",
+			Layout:         "",
 		},
 		Archive: ArchiveOpts{
 			AfterDays:                14,
@@ -113,24 +271,99 @@ func getDefaultOpts() Opts {
 			SectionContentSuffix:     "]",
 			SectionContentTimeFormat: "2006-01-02",
 			MonthTimeFormat:          "Jan2006",
+			Period:                   "month",
+			Compress:                 "",
+			SectionPolicies:          map[string]string{},
+			TableOfContents:          false,
+			Workers:                  0,
 		},
 		Cli: CliOpts{
 			TimeFormat: "2006-01-02",
 		},
+		Periods: PeriodsOpts{
+			Weekly: PeriodOpts{
+				FileTimeFormat:   "week-2006-01-02",
+				HeaderTimeFormat: "Week of [Mon] 02 Jan 2006",
+				Sections: []string{
+					"GOALS",
+					"PLAN",
+					"NOTES",
+				},
+			},
+			Monthly: PeriodOpts{
+				FileTimeFormat:   "month-2006-01",
+				HeaderTimeFormat: "January 2006",
+				Sections: []string{
+					"GOALS",
+					"REVIEW",
+					"NOTES",
+				},
+			},
+		},
+		Add: AddOpts{
+			TimestampFormat: "",
+		},
+		Editor: EditorOpts{
+			Command: "",
+		},
 		TemplateFileCountThresh: 90,
 	}
 }
 
-// Load loads the configuration from file and/or evironment
-func Load() (Opts, error) {
+// Load loads the configuration of a selected notebook from file and/or evironment
+func Load(sel Selection) (Opts, error) {
+	loc, err := sel.locate()
+	if err != nil {
+		return Opts{}, err
+	}
+
+	opts, err := loadOpts(loc)
+	if err != nil {
+		return opts, err
+	}
+
+	problems := validateOpts(opts)
+	if len(problems) > 0 {
+		// problems are reported without lines if the files cannot be reread
+		if files, err := getConfigFiles(loc); err == nil {
+			_ = problems.locateInFiles(files)
+		}
+		return opts, fmt.Errorf("configuration error: %w", problems)
+	}
+
+	return opts, nil
+}
+
+// loadOpts loads the configuration of a location from the configuration files in the search path and/or environment
+// without validation
+func loadOpts(loc location) (Opts, error) {
+	return readOpts(loc, true)
+}
+
+// readOpts reads the configuration of a location from the configuration files in the search path and, if specified,
+// the environment without validation
+func readOpts(loc location, withEnv bool) (Opts, error) {
 	opts := Opts{}
 
-	// parse config file allowing environment variable overrides
-	err := loadFromEnv(GetConfigFilePath(), &opts)
+	files, err := getConfigFiles(loc)
+	if err != nil {
+		return opts, err
+	}
+
+	// parse config files, layered by precedence
+	err = loadFromFiles(files, &opts)
 	if err != nil {
 		return opts, fmt.Errorf("unable to read config file: %w", err)
 	}
 
+	// allow environment variable overrides
+	if withEnv {
+		err = cleanenv.ReadEnv(&opts)
+		if err != nil {
+			return opts, fmt.Errorf("unable to read configuration from environment: %w", err)
+		}
+	}
+
 	// overwrite defaults with opts from file/env
 	defaults := getDefaultOpts()
 	err = mergo.Merge(&opts, defaults)
@@ -138,69 +371,107 @@ func Load() (Opts, error) {
 		return opts, fmt.Errorf("unable to integrate configuration from file with defaults: %w", err)
 	}
 
-	// set AppDir as read from environment
-	opts.AppDir = appDir
-
-	err = ValidateOpts(opts)
-	if err != nil {
-		return opts, fmt.Errorf("configuration error in [%s]: %w", fileName, err)
+	// sections configured as items take precedence over section names
+	if len(opts.Section.Items) > 0 {
+		opts.Section.Names = []string{}
+		for _, item := range opts.Section.Items {
+			opts.Section.Names = append(opts.Section.Names, item.Name)
+		}
 	}
 
+	// set AppDir as resolved from environment or notebook registry
+	opts.AppDir = loc.appDir
+
 	return opts, nil
 }
 
-func loadFromEnv(path string, opts *Opts) error {
-	err := cleanenv.ReadConfig(path, opts)
+// CreateIfNotExists writes defaults to the configuration file in an application directory if no configuration file
+// exists in the search path, including a configuration file selected by flag
+func CreateIfNotExists(appDir string, configFile string) error {
+	files, err := getConfigFiles(location{appDir: appDir, configFile: configFile})
 	if err != nil {
 		return err
 	}
+	for _, f := range files {
+		if f.Exists {
+			// config file exists, nothing to do
+			return nil
+		}
+	}
 
-	err = loadBackCompat(path, opts)
+	configPath := GetConfigFilePath(appDir)
+
+	defaults := getDefaultOpts()
+	yml, err := yaml.Marshal(defaults)
 	if err != nil {
-		return fmt.Errorf("unable to read config file for backwards compatibility fields: %w", err)
+		return fmt.Errorf("unable to generate config file: %w", err)
 	}
-
+	err = os.WriteFile(configPath, yml, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to create configuration file [%s]: %w", configPath, err)
+	}
+	log.Printf("created default configuration file: [%s]", configPath)
 	return nil
 }
 
-func loadBackCompat(path string, opts *Opts) error {
-	// TemplateFileCountThresh backwards compatibility with previously typo'd field
-	if opts.TemplateFileCountThresh != 0 {
-		return nil
+// Update overwrites the notebook's configuration file of a selected notebook, or the configuration file selected by the
+// --config flag, with the active configuration at the current version, including values set by environment variables
+// and parameters added by new versions, keeping a backup of the file. Other existing configuration files in the search
+// path of a previous version are upgraded in place, preserving the comments and ordering of each file and keeping a
+// backup of each upgraded file.
+func Update(sel Selection) error {
+	loc, err := sel.locate()
+	if err != nil {
+		return err
 	}
-	backcompat := OptsBackCompat{}
-	err := cleanenv.ReadConfig(GetConfigFilePath(), &backcompat)
+	opts, err := Load(sel)
 	if err != nil {
 		return err
 	}
-	opts.TemplateFileCountThresh = backcompat.TemplateFileCountThresh
-	return nil
-}
-
-// CreateIfNotExists writes defaults to the configuration file if it does not already exist
-func CreateIfNotExists() error {
-	configPath := GetConfigFilePath()
-	_, err := os.Stat(configPath)
-	if !os.IsNotExist(err) {
-		// config file exists, nothing to do
-		return nil
+	yml, err := yaml.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("unable to generate config file: %w", err)
 	}
 
-	defaults := getDefaultOpts()
-	yml, err := yaml.Marshal(defaults)
+	configPath := getTargetConfigFile(loc)
+	files, err := getConfigFiles(loc)
 	if err != nil {
-		return fmt.Errorf("unable to generate config file: %w", err)
+		return err
+	}
+	for _, f := range files {
+		if !f.Exists || f.Path == configPath {
+			continue
+		}
+		raw, doc, upgraded, err := upgradeConfigFile(f.Path)
+		if err != nil {
+			return err
+		}
+		if !upgraded {
+			continue
+		}
+		err = writeConfigFile(f.Path, doc, detectIndent(raw))
+		if err != nil {
+			return err
+		}
+	}
+
+	if fileExists(configPath) {
+		backup, err := backupFile(configPath)
+		if err != nil {
+			return err
+		}
+		log.Printf("backed up configuration file [%s] to [%s]", configPath, backup)
 	}
 	err = os.WriteFile(configPath, yml, 0o644)
 	if err != nil {
-		return fmt.Errorf("unable to create configuration file [%s]: %w", configPath, err)
+		return fmt.Errorf("unable to write configuration file [%s]: %w", configPath, err)
 	}
-	log.Printf("created default configuration file: [%s]", configPath)
+	log.Printf("updated configuration file [%s] with the active configuration", configPath)
 	return nil
 }
 
 // EnsureAppDir validates that the application directory exists or is created
-func EnsureAppDir() error {
+func EnsureAppDir(appDir string) error {
 	if appDir == "" {
 		return fmt.Errorf("required environment variable [%s] is not set", envAppDir)
 	}
@@ -216,57 +487,215 @@ func EnsureAppDir() error {
 	}
 
 	if !finfo.IsDir() {
-		return fmt.Errorf("[%s=%s] must be a directory", envAppDir, appDir)
+		return fmt.Errorf("application directory [%s] must be a directory", appDir)
 	}
 	return nil
 }
 
-// ValidateOpts returns an error if the specified options are misconfigured
+// ValidateOpts returns an error reporting all problems found in the specified options
 func ValidateOpts(opts Opts) error {
+	problems := validateOpts(opts)
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// validateOpts returns all problems found in the specified options
+func validateOpts(opts Opts) Problems {
+	problems := Problems{}
+
 	// validate appDir is not empty
 	if opts.AppDir == "" {
-		return fmt.Errorf("must include path to application directory in %s environment variable", envAppDir)
+		problems.add(envAppDir, "must include path to application directory in %s environment variable", envAppDir)
 	}
 
-	// validate at least one section
-	if len(opts.Section.Names) == 0 {
-		return errors.New("must include at least one section")
-	}
+	// validate sections of daily notes
+	problems.addErr("section.names", validateSectionNames(opts.Section.Names))
 
-	// validate section names are unique
-	uniq := map[string]struct{}{}
-	for _, name := range opts.Section.Names {
-		uniq[name] = struct{}{}
+	// validate sections configured as items
+	problems = append(problems, validateSectionItems(opts.Section.Items)...)
+
+	// validate sections of weekly and monthly notes
+	problems.addErr("periods.weekly.sections", validateSectionNames(opts.Periods.Weekly.Sections))
+	problems.addErr("periods.monthly.sections", validateSectionNames(opts.Periods.Monthly.Sections))
+
+	// validate file name formats are distinct: this is needed for determining the period of a note from its file name
+	fileTimeFormats := map[string]struct{}{
+		opts.File.TimeFormat:                {},
+		opts.Periods.Weekly.FileTimeFormat:  {},
+		opts.Periods.Monthly.FileTimeFormat: {},
 	}
-	if len(uniq) != len(opts.Section.Names) {
-		return errors.New("section names must be unique")
+	if len(fileTimeFormats) != 3 {
+		problems.add("file.timeFormat", "file time formats for daily, weekly, and monthly notes must be distinct")
 	}
 
 	// validate file archive prefix: this is needed for determining if a file is an archive
 	if opts.Archive.FilePrefix == "" || strings.ReplaceAll(opts.Archive.FilePrefix, " ", "") == "" {
-		return errors.New("file prefix for archives must not be empty")
+		problems.add("archive.filePrefix", "file prefix for archives must not be empty")
+	}
+
+	// validate archive period
+	if _, found := archivePeriods[opts.Archive.Period]; !found {
+		problems.add("archive.period", "archive period [%s] must be one of week, month, quarter, or year", opts.Archive.Period)
+	}
+
+	// validate archive compression
+	if _, found := archiveCompressions[opts.Archive.Compress]; !found {
+		problems.add("archive.compress", "archive compression [%s] must be one of gzip or zstd, or empty for no compression", opts.Archive.Compress)
+	}
+
+	// validate archive section policies
+	for _, section := range sortedKeys(opts.Archive.SectionPolicies) {
+		policy := opts.Archive.SectionPolicies[section]
+		path := "archive.sectionPolicies." + section
+		if !slices.Contains(opts.Section.Names, section) {
+			problems.add(path, "archive policy defined for unknown section [%s]", section)
+		}
+		if policy != SectionPolicyKeep && policy != SectionPolicyDrop && policy != SectionPolicySeparate {
+			problems.add(path, "archive policy [%s] of section [%s] must be one of keep, drop, or separate", policy, section)
+		}
 	}
 
 	// validate archive after days is at least 1
 	if opts.Archive.AfterDays < 1 {
-		return errors.New("archive after days must be greater than or equal to 1")
+		problems.add("archive.afterDays", "archive after days must be greater than or equal to 1")
+	}
+
+	// validate archive workers is not negative
+	if opts.Archive.Workers < 0 {
+		problems.add("archive.workers", "archive workers must not be negative")
 	}
 
 	// validate file extension does not contain leading dot
 	if strings.HasPrefix(opts.File.Ext, ".") {
-		return errors.New("file extension must not include leading dot")
+		problems.add("file.ext", "file extension must not include leading dot")
+	}
+
+	// validate named notes directory is a single subdirectory of the application directory
+	if opts.File.NotesDir == "" || opts.File.NotesDir != filepath.Base(opts.File.NotesDir) || strings.HasPrefix(opts.File.NotesDir, ".") {
+		problems.add("file.notesDir", "notes directory must be the name of a non-hidden subdirectory")
 	}
 
+	// validate layout for dated notes
+	problems.addErr("file.layout", validateLayout(opts.File.Layout))
+
 	// validate the file cursor line is not negative
 	if opts.File.CursorLine < 0 {
-		return errors.New("cursor line must not be negative")
+		problems.add("file.cursorLine", "cursor line must not be negative")
+	}
+
+	// validate editor command can be split into words
+	if _, err := editor.SplitCommand(opts.Editor.Command); err != nil {
+		problems.addErr("editor.command", err)
+	}
+
+	// validate the cursor section is a section of daily, weekly, or monthly notes and is not also set by a section item
+	if section := opts.File.CursorSection; section != "" {
+		if !slices.Contains(opts.Section.Names, section) && !slices.Contains(opts.Periods.Weekly.Sections, section) && !slices.Contains(opts.Periods.Monthly.Sections, section) {
+			problems.add("file.cursorSection", "cursor section [%s] must be a section of daily, weekly, or monthly notes", section)
+		}
+		if slices.ContainsFunc(opts.Section.Items, func(item SectionItem) bool { return item.Cursor }) {
+			problems.add("file.cursorSection", "cursor section must not be set when a section item sets the cursor")
+		}
+	}
+
+	// validate cursor position
+	if opts.File.CursorPosition != "" && opts.File.CursorPosition != CursorPositionStart && opts.File.CursorPosition != CursorPositionEnd {
+		problems.add("file.cursorPosition", "cursor position [%s] must be one of start or end", opts.File.CursorPosition)
 	}
 
 	// validate threshold for warning on too many template files is larger than archive after days
 	if opts.TemplateFileCountThresh <= opts.Archive.AfterDays {
-		return errors.New("template file count threshold must be larger than archive after days")
+		problems.add("templateFileCountThresh", "template file count threshold must be larger than archive after days")
+	}
+
+	return problems
+}
+
+// validateSectionItems returns problems for section items with invalid names, aliases, and policies
+func validateSectionItems(items []SectionItem) Problems {
+	problems := Problems{}
+	if len(items) == 0 {
+		return problems
+	}
+
+	names := map[string]struct{}{}
+	for _, item := range items {
+		names[item.Name] = struct{}{}
+	}
+
+	aliases := map[string]struct{}{}
+	numCursors := 0
+	for _, item := range items {
+		if strings.TrimSpace(item.Name) == "" {
+			problems.add("section.items", "section items must have a name")
+		}
+		for _, alias := range item.Aliases {
+			_, isName := names[alias]
+			_, isAlias := aliases[alias]
+			if strings.TrimSpace(alias) == "" || isName || isAlias {
+				problems.add("section.items", "alias [%s] of section [%s] must be non-empty and distinct from all section names and aliases", alias, item.Name)
+			}
+			aliases[alias] = struct{}{}
+		}
+		if item.TrailingNewlines < 0 {
+			problems.add("section.items", "trailing newlines of section [%s] must not be negative", item.Name)
+		}
+		if item.CarryForward != "" && item.CarryForward != CarryForwardCopy && item.CarryForward != CarryForwardMove {
+			problems.add("section.items", "carry forward policy [%s] of section [%s] must be one of copy or move, or empty for none", item.CarryForward, item.Name)
+		}
+		if item.ArchivePolicy != "" && item.ArchivePolicy != SectionPolicyKeep && item.ArchivePolicy != SectionPolicyDrop && item.ArchivePolicy != SectionPolicySeparate {
+			problems.add("section.items", "archive policy [%s] of section [%s] must be one of keep, drop, or separate", item.ArchivePolicy, item.Name)
+		}
+		if item.Cursor {
+			numCursors++
+		}
+	}
+	if len(names) != len(items) {
+		problems.add("section.items", "section names must be unique")
+	}
+	if numCursors > 1 {
+		problems.add("section.items", "cursor must be placed in at most one section")
+	}
+	return problems
+}
+
+func validateSectionNames(names []string) error {
+	// validate at least one section
+	if len(names) == 0 {
+		return errors.New("must include at least one section")
+	}
+
+	// validate section names are unique
+	uniq := map[string]struct{}{}
+	for _, name := range names {
+		uniq[name] = struct{}{}
 	}
+	if len(uniq) != len(names) {
+		return errors.New("section names must be unique")
+	}
+
+	return nil
+}
 
+func validateLayout(layout string) error {
+	if layout == "" {
+		return nil
+	}
+	if filepath.IsAbs(layout) || strings.HasPrefix(layout, "/") {
+		return fmt.Errorf("file layout [%s] must be relative to the application directory", layout)
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(layout), "/") {
+		if dir == ".." || strings.HasPrefix(dir, ".") {
+			return fmt.Errorf("file layout [%s] must not contain parent or hidden directories", layout)
+		}
+	}
+	for _, match := range layoutPlaceholderRegex.FindAllStringSubmatch(layout, -1) {
+		if _, found := layoutPlaceholders[match[1]]; !found {
+			return fmt.Errorf("file layout [%s] contains unknown placeholder [%s]", layout, match[0])
+		}
+	}
 	return nil
 }
 
@@ -280,18 +709,22 @@ func DescribeEnvVars() string {
 	return description
 }
 
-// GetConfigFilePath constructs the full path to the configuration file
-func GetConfigFilePath() string {
+// GetConfigFilePath constructs the full path to the configuration file in an application directory
+func GetConfigFilePath(appDir string) string {
 	return filepath.Join(appDir, fileName)
 }
 
-// InitApp initializes the application by ensuring the necessary directories and files exist
-func InitApp() error {
-	err := EnsureAppDir()
+// InitApp initializes the application for a selected notebook by ensuring the necessary directories and files exist
+func InitApp(sel Selection) error {
+	loc, err := sel.locate()
+	if err != nil {
+		return err
+	}
+	err = EnsureAppDir(loc.appDir)
 	if err != nil {
 		return err
 	}
-	err = CreateIfNotExists()
+	err = CreateIfNotExists(loc.appDir, loc.configFile)
 	if err != nil {
 		return err
 	}
@@ -43,6 +43,37 @@ func TestValidateOpts(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("no weekly section names", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Periods.Weekly.Sections = []string{}
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("monthly section names are not unique", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Periods.Monthly.Sections = []string{
+			"section1",
+			"section1",
+		}
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("weekly file time format matches daily file time format", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Periods.Weekly.FileTimeFormat = opts.File.TimeFormat
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("weekly file time format matches monthly file time format", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Periods.Weekly.FileTimeFormat = opts.Periods.Monthly.FileTimeFormat
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
 	t.Run("archive file prefix is empty string", func(t *testing.T) {
 		opts := getTestOpts()
 		opts.Archive.FilePrefix = ""
@@ -64,6 +95,67 @@ func TestValidateOpts(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("archive period is valid", func(t *testing.T) {
+		for _, period := range []string{"week", "month", "quarter", "year"} {
+			opts := getTestOpts()
+			opts.Archive.Period = period
+			err := ValidateOpts(opts)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("archive period is invalid", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Archive.Period = "day"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("archive compression is valid", func(t *testing.T) {
+		for _, compression := range []string{"", "gzip", "zstd"} {
+			opts := getTestOpts()
+			opts.Archive.Compress = compression
+			err := ValidateOpts(opts)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("archive compression is invalid", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Archive.Compress = "zip"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("archive section policies are valid", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Archive.SectionPolicies = map[string]string{
+			opts.Section.Names[0]: "keep",
+			opts.Section.Names[1]: "drop",
+			opts.Section.Names[2]: "separate",
+		}
+		err := ValidateOpts(opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("archive section policy is invalid", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Archive.SectionPolicies = map[string]string{
+			opts.Section.Names[0]: "archive",
+		}
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("archive section policy is defined for unknown section", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Archive.SectionPolicies = map[string]string{
+			"unknown": "drop",
+		}
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
 	t.Run("archive after days is negative", func(t *testing.T) {
 		opts := getTestOpts()
 		opts.Archive.AfterDays = -1
@@ -78,6 +170,13 @@ func TestValidateOpts(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("archive workers is negative", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Archive.Workers = -1
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
 	t.Run("archive after days is one", func(t *testing.T) {
 		opts := getTestOpts()
 		opts.Archive.AfterDays = 1
@@ -106,6 +205,69 @@ func TestValidateOpts(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("notes directory is empty", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.NotesDir = ""
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("notes directory is nested", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.NotesDir = "notes/nested"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("notes directory is parent directory", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.NotesDir = ".."
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("notes directory is a subdirectory", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.NotesDir = "projects"
+		err := ValidateOpts(opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("empty layout", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.Layout = ""
+		err := ValidateOpts(opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("layout with placeholders", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.Layout = "{{year}}/{{month}}/"
+		err := ValidateOpts(opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("layout with unknown placeholder", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.Layout = "{{year}}/{{week}}/"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("absolute layout", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.Layout = "/{{year}}/"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("layout with parent directory", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.Layout = "../{{year}}/"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
 	t.Run("file cursor line is negative", func(t *testing.T) {
 		opts := getTestOpts()
 		opts.File.CursorLine = -2
@@ -120,6 +282,50 @@ func TestValidateOpts(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("cursor section of weekly notes", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.CursorSection = "GOALS"
+		err := ValidateOpts(opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("undefined cursor section", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.CursorSection = "undefined"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("cursor section set with section item cursor", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.CursorSection = "TODO"
+		opts.Section.Items = []SectionItem{{Name: "TODO"}, {Name: "DONE", Cursor: true}}
+		opts.Section.Names = []string{"TODO", "DONE"}
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid cursor position", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.File.CursorPosition = "middle"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("editor command with unterminated quote", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Editor.Command = "code --goto '{file}:{line}"
+		err := ValidateOpts(opts)
+		require.Error(t, err)
+	})
+
+	t.Run("editor command template", func(t *testing.T) {
+		opts := getTestOpts()
+		opts.Editor.Command = "code --wait --goto '{file}:{line}'"
+		err := ValidateOpts(opts)
+		require.NoError(t, err)
+	})
+
 	t.Run("template file count threshold not greater than archive after days should error", func(t *testing.T) {
 		opts := getTestOpts()
 		opts.Archive.AfterDays = 100
@@ -137,8 +343,241 @@ func TestValidateOpts(t *testing.T) {
 	})
 }
 
+func TestValidateSectionItems(t *testing.T) {
+	type testCase struct {
+		items       []SectionItem
+		shouldError bool
+	}
+
+	tests := map[string]testCase{
+		"no items": {
+			items:       []SectionItem{},
+			shouldError: false,
+		},
+		"valid items": {
+			items: []SectionItem{
+				{Name: "TODO", Aliases: []string{"Tasks"}, CarryForward: CarryForwardMove, Cursor: true},
+				{Name: "DONE", ArchivePolicy: SectionPolicyDrop, TrailingNewlines: 1},
+				{Name: "NOTES", DefaultContent: "- ", CarryForward: CarryForwardCopy},
+			},
+			shouldError: false,
+		},
+		"empty name": {
+			items: []SectionItem{
+				{Name: ""},
+			},
+			shouldError: true,
+		},
+		"duplicate names": {
+			items: []SectionItem{
+				{Name: "TODO"},
+				{Name: "TODO"},
+			},
+			shouldError: true,
+		},
+		"alias of another section name": {
+			items: []SectionItem{
+				{Name: "TODO", Aliases: []string{"DONE"}},
+				{Name: "DONE"},
+			},
+			shouldError: true,
+		},
+		"duplicate aliases": {
+			items: []SectionItem{
+				{Name: "TODO", Aliases: []string{"Tasks"}},
+				{Name: "DONE", Aliases: []string{"Tasks"}},
+			},
+			shouldError: true,
+		},
+		"negative trailing newlines": {
+			items: []SectionItem{
+				{Name: "TODO", TrailingNewlines: -1},
+			},
+			shouldError: true,
+		},
+		"invalid carry forward policy": {
+			items: []SectionItem{
+				{Name: "TODO", CarryForward: "duplicate"},
+			},
+			shouldError: true,
+		},
+		"invalid archive policy": {
+			items: []SectionItem{
+				{Name: "TODO", ArchivePolicy: "discard"},
+			},
+			shouldError: true,
+		},
+		"multiple cursors": {
+			items: []SectionItem{
+				{Name: "TODO", Cursor: true},
+				{Name: "DONE", Cursor: true},
+			},
+			shouldError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			problems := validateSectionItems(test.items)
+			if test.shouldError {
+				require.NotEmpty(t, problems)
+				return
+			}
+			require.Empty(t, problems)
+		})
+	}
+}
+
+func TestSectionItemOpts(t *testing.T) {
+	opts := getTestOpts()
+	opts.Section.TrailingNewlines = 2
+	opts.Archive.SectionPolicies = map[string]string{"TODO": SectionPolicyDrop, "DONE": SectionPolicyDrop}
+	opts.Section.Items = []SectionItem{
+		{Name: "TODO", CarryForward: CarryForwardMove, ArchivePolicy: SectionPolicySeparate},
+		{Name: "DONE", TrailingNewlines: 1, Cursor: true},
+		{Name: "NOTES", CarryForward: CarryForwardCopy},
+	}
+
+	require.Equal(t, 2, opts.Section.GetTrailingNewlines("TODO"))
+	require.Equal(t, 1, opts.Section.GetTrailingNewlines("DONE"))
+	require.Equal(t, 2, opts.Section.GetTrailingNewlines("undefined"))
+
+	require.Equal(t, "DONE", opts.GetCursorSection())
+	opts.File.CursorSection = "NOTES"
+	require.Equal(t, "NOTES", opts.GetCursorSection())
+
+	copied, moved := opts.Section.GetCarryForwardSections()
+	require.Equal(t, []string{"NOTES"}, copied)
+	require.Equal(t, []string{"TODO"}, moved)
+
+	require.Equal(t, SectionPolicySeparate, opts.GetSectionPolicy("TODO"))
+	require.Equal(t, SectionPolicyDrop, opts.GetSectionPolicy("DONE"))
+	require.Equal(t, SectionPolicyKeep, opts.GetSectionPolicy("NOTES"))
+}
+
 func getTestOpts() Opts {
 	opts := getDefaultOpts()
 	opts.AppDir = "path/to/appDir"
 	return opts
 }
+
+func TestValidate(t *testing.T) {
+	t.Run("default options are valid", func(t *testing.T) {
+		problems, err := Validate(getTestOpts(), nil)
+		require.NoError(t, err)
+		require.Empty(t, problems)
+	})
+
+	t.Run("all problems are reported with paths and lines", func(t *testing.T) {
+		configFile := []byte(`header:
+  timeFormat: "[Mon] 02 Jan"
+file:
+  ext: .txt
+archive:
+  period: decade
+  sectionPolicies:
+    UNKNOWN: drop
+`)
+		opts := getTestOpts()
+		opts.Header.TimeFormat = "[Mon] 02 Jan"
+		opts.File.Ext = ".txt"
+		opts.Archive.Period = "decade"
+		opts.Archive.SectionPolicies = map[string]string{"UNKNOWN": "drop"}
+
+		problems, err := Validate(opts, configFile)
+		require.NoError(t, err)
+
+		lines := map[string]int{}
+		for _, problem := range problems {
+			lines[problem.Path] = problem.Line
+		}
+		require.Equal(t, map[string]int{
+			"header.timeFormat":               2,
+			"file.ext":                        4,
+			"archive.period":                  6,
+			"archive.sectionPolicies.UNKNOWN": 8,
+		}, lines)
+	})
+
+	type testCase struct {
+		modify       func(*Opts)
+		expectedPath string
+	}
+
+	tests := map[string]testCase{
+		"file time format does not round-trip": {
+			modify:       func(o *Opts) { o.File.TimeFormat = "01-02" },
+			expectedPath: "file.timeFormat",
+		},
+		"cli time format does not round-trip": {
+			modify:       func(o *Opts) { o.Cli.TimeFormat = "2006-01" },
+			expectedPath: "cli.timeFormat",
+		},
+		"archive month time format does not round-trip": {
+			modify:       func(o *Opts) { o.Archive.MonthTimeFormat = "Jan" },
+			expectedPath: "archive.monthTimeFormat",
+		},
+		"archive section content time format does not round-trip": {
+			modify:       func(o *Opts) { o.Archive.SectionContentTimeFormat = "Jan 02" },
+			expectedPath: "archive.sectionContentTimeFormat",
+		},
+		"section prefix and suffix form an invalid pattern": {
+			modify:       func(o *Opts) { o.Section.Prefix = "[" },
+			expectedPath: "section.prefix",
+		},
+		"section prefix and suffix match all text": {
+			modify: func(o *Opts) {
+				o.Section.Prefix = ""
+				o.Section.Suffix = ""
+			},
+			expectedPath: "section.prefix",
+		},
+		"section prefix and suffix match archive content headers": {
+			modify: func(o *Opts) {
+				o.Section.Prefix = `\[`
+				o.Section.Suffix = `\]`
+			},
+			expectedPath: "section.prefix",
+		},
+		"archive prefix collides with note file names": {
+			modify:       func(o *Opts) { o.Archive.FilePrefix = "20" },
+			expectedPath: "archive.filePrefix",
+		},
+		"archive file names can be parsed as note file names": {
+			modify: func(o *Opts) {
+				o.Archive.FilePrefix = "notes-"
+				o.File.TimeFormat = "notes-Jan2006-02"
+				o.Archive.MonthTimeFormat = "Jan2006-02"
+			},
+			expectedPath: "archive.filePrefix",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := getTestOpts()
+			test.modify(&opts)
+			problems, err := Validate(opts, nil)
+			require.NoError(t, err)
+			require.NotEmpty(t, problems)
+			for _, problem := range problems {
+				require.Equal(t, test.expectedPath, problem.Path, problem.Message)
+				require.Zero(t, problem.Line)
+			}
+		})
+	}
+}
+
+func TestProblemsError(t *testing.T) {
+	problems := Problems{
+		{Path: "archive.period", File: "/notes/.config.yml", Line: 6, Message: "unknown archive period [decade]"},
+		{Path: "file.ext", Line: 4, Message: "file extension must not include leading dot"},
+		{Path: "cli.timeFormat", Message: "time format does not round-trip"},
+	}
+	require.Equal(t,
+		"archive.period (/notes/.config.yml line 6): unknown archive period [decade]; "+
+			"file.ext (line 4): file extension must not include leading dot; "+
+			"cli.timeFormat: time format does not round-trip",
+		problems.Error(),
+	)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Difference is a configuration parameter with an active value that differs from its default or is set by an
+// environment variable
+type Difference struct {
+	Key     string
+	Active  string // active value
+	Source  string // configuration files or environment variable setting the active value
+	Default string // default value
+	File    string // value set by configuration files that is overridden by an environment variable, empty otherwise
+}
+
+// Diff returns the differences of the active configuration of a selected notebook from the defaults, with values
+// formatted as single-line yaml
+func Diff(sel Selection) ([]Difference, error) {
+	loc, err := sel.locate()
+	if err != nil {
+		return nil, err
+	}
+	active, err := readOpts(loc, true)
+	if err != nil {
+		return nil, err
+	}
+	fromFiles, err := readOpts(loc, false)
+	if err != nil {
+		return nil, err
+	}
+	defaults := getDefaultOpts()
+
+	_, sources, err := GetSources(sel)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []Difference{}
+	for _, source := range sources {
+		activeValue, err := formatParameter(&active, source.Key)
+		if err != nil {
+			return nil, err
+		}
+		defaultValue, err := formatParameter(&defaults, source.Key)
+		if err != nil {
+			return nil, err
+		}
+		fileValue, err := formatParameter(&fromFiles, source.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		fromEnv := source.Env != ""
+		if activeValue == defaultValue && !fromEnv {
+			continue
+		}
+		diff := Difference{
+			Key:     source.Key,
+			Active:  activeValue,
+			Source:  strings.Join(source.From, ", "),
+			Default: defaultValue,
+		}
+		if fromEnv && fileValue != defaultValue {
+			diff.File = fileValue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// formatParameter formats the value of a configuration parameter as single-line yaml
+func formatParameter(opts *Opts, key string) (string, error) {
+	f, err := lookupField(opts, key)
+	if err != nil {
+		return "", err
+	}
+	node := yaml.Node{}
+	err = node.Encode(f.value.Interface())
+	if err != nil {
+		return "", fmt.Errorf("unable to format [%s]: %w", key, err)
+	}
+	setFlowStyle(&node)
+	yml, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", fmt.Errorf("unable to format [%s]: %w", key, err)
+	}
+	return strings.TrimSuffix(string(yml), "\n"), nil
+}
+
+func setFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+		node.Style = yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	type testCase struct {
+		files    configFiles
+		env      map[string]string
+		expected func(paths configFiles) []Difference
+	}
+
+	tests := map[string]testCase{
+		"defaults": {
+			files:    configFiles{appDir: "version: 1\narchive:\n  afterDays: 14\n"},
+			expected: func(paths configFiles) []Difference { return []Difference{} },
+		},
+		"values from files": {
+			files: configFiles{
+				userDir: "header:\n  prefix: '# '\n",
+				appDir:  "section:\n  names: [TODO, DONE]\narchive:\n  sectionPolicies:\n    DONE: drop\n",
+			},
+			expected: func(paths configFiles) []Difference {
+				return []Difference{
+					{Key: "header.prefix", Active: "'# '", Source: paths.userDir, Default: `""`},
+					{Key: "section.names", Active: "[TODO, DONE]", Source: paths.appDir, Default: "[TODO, DONE, NOTES]"},
+					{Key: "archive.sectionPolicies", Active: "{DONE: drop}", Source: paths.appDir, Default: "{}"},
+				}
+			},
+		},
+		"environment variables override files and defaults": {
+			files: configFiles{
+				appDir: "section:\n  names: [TODO, DONE]\n",
+			},
+			env: map[string]string{
+				"TEXTNOTE_SECTION_NAMES":           "A,B",
+				"TEXTNOTE_ARCHIVE_PERIOD":          "month",
+				"TEXTNOTE_PERIODS_WEEKLY_SECTIONS": "PLAN",
+			},
+			expected: func(paths configFiles) []Difference {
+				return []Difference{
+					{Key: "section.names", Active: "[A, B]", Source: "environment variable [TEXTNOTE_SECTION_NAMES]", Default: "[TODO, DONE, NOTES]", File: "[TODO, DONE]"},
+					{Key: "archive.period", Active: "month", Source: "environment variable [TEXTNOTE_ARCHIVE_PERIOD]", Default: "month"},
+					{Key: "periods.weekly.sections", Active: "[PLAN]", Source: "environment variable [TEXTNOTE_PERIODS_WEEKLY_SECTIONS]", Default: "[GOALS, PLAN, NOTES]"},
+				}
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			loc, paths := setupConfigFiles(t, test.files)
+			t.Setenv(envAppDir, loc.appDir)
+			t.Setenv(envNotebook, "")
+			for env, value := range test.env {
+				t.Setenv(env, value)
+			}
+
+			diffs, err := Diff(Selection{ConfigFile: loc.configFile})
+			require.NoError(t, err)
+			require.Equal(t, test.expected(paths), diffs)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const (
+	// envConfigFile is the name of the environment variable specifying a configuration file
+	envConfigFile = "TEXTNOTE_CONFIG"
+	// userFileName is the name of the configuration file within the user's configuration directory
+	userFileName = "config.yml"
+
+	sourceFlag    = "--config flag"
+	sourceEnv     = envConfigFile
+	sourceUserDir = "user configuration directory"
+	sourceAppDir  = "application directory"
+	sourceDefault = "default"
+)
+
+// Selection selects the notebook and configuration file of a command, as set by global flags
+type Selection struct {
+	Notebook   string // name of the notebook, empty to select the notebook from the environment or registry
+	ConfigFile string // configuration file taking precedence over all other configuration files, empty if not selected
+}
+
+// location is the application directory of a selected notebook and the configuration file selected with it
+type location struct {
+	appDir     string
+	configFile string
+}
+
+func (s Selection) locate() (location, error) {
+	appDir, err := GetAppDir(s.Notebook)
+	if err != nil {
+		return location{}, err
+	}
+	return location{appDir: appDir, configFile: s.ConfigFile}, nil
+}
+
+// File is a configuration file in the search path
+type File struct {
+	Path   string
+	Source string // how the file is found in the search path
+	Exists bool
+}
+
+// getConfigFiles returns the configuration files of a location in order of precedence, highest first:
+// the file selected by the --config flag, the file specified by the TEXTNOTE_CONFIG environment variable, the file in
+// the user's configuration directory, and the file in the application directory. The files selected by the flag and
+// environment variable are only included when set and must exist.
+func getConfigFiles(loc location) ([]File, error) {
+	files := []File{}
+
+	for _, selected := range []struct {
+		path   string
+		source string
+	}{
+		{loc.configFile, sourceFlag},
+		{os.Getenv(envConfigFile), sourceEnv},
+	} {
+		if selected.path == "" {
+			continue
+		}
+		path := expandHome(selected.path)
+		if !fileExists(path) {
+			return files, fmt.Errorf("configuration file [%s] specified by %s does not exist", path, selected.source)
+		}
+		files = append(files, File{Path: path, Source: selected.source, Exists: true})
+	}
+
+	if userDir, err := getUserConfigDir(); err == nil {
+		path := filepath.Join(userDir, registryDirName, userFileName)
+		files = append(files, File{Path: path, Source: sourceUserDir, Exists: fileExists(path)})
+	}
+
+	path := GetConfigFilePath(loc.appDir)
+	files = append(files, File{Path: path, Source: sourceAppDir, Exists: fileExists(path)})
+
+	return files, nil
+}
+
+// GetConfigFiles returns the configuration files in the search path of a selected notebook in order of precedence,
+// highest first
+func GetConfigFiles(sel Selection) ([]File, error) {
+	loc, err := sel.locate()
+	if err != nil {
+		return nil, err
+	}
+	return getConfigFiles(loc)
+}
+
+// GetConfigFile returns the path of the configuration file of a selected notebook that is displayed, edited, and set
+// by the config command, which is the configuration file selected by the --config flag if any and otherwise the
+// configuration file in the notebook's application directory
+func GetConfigFile(sel Selection) (string, error) {
+	loc, err := sel.locate()
+	if err != nil {
+		return "", err
+	}
+	return getTargetConfigFile(loc), nil
+}
+
+// getTargetConfigFile returns the configuration file selected by flag for a location or otherwise the configuration file
+// in the location's application directory, but never a configuration file shared by all notebooks
+func getTargetConfigFile(loc location) string {
+	if loc.configFile != "" {
+		return expandHome(loc.configFile)
+	}
+	return GetConfigFilePath(loc.appDir)
+}
+
+// loadFromFiles parses existing configuration files, given highest precedence first, such that each file overrides
+// the files of lower precedence, upgrading each file to the current version
+func loadFromFiles(files []File, opts *Opts) error {
+	for i := len(files) - 1; i >= 0; i-- {
+		if !files[i].Exists {
+			continue
+		}
+		doc, _, err := readConfigFile(files[i].Path)
+		if err != nil {
+			return err
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		err = doc.Decode(opts)
+		if err != nil {
+			return fmt.Errorf("unable to parse [%s]: %w", files[i].Path, err)
+		}
+	}
+	return nil
+}
+
+// Source is the origin of the active value of a configuration parameter
+type Source struct {
+	Key  string
+	From []string // paths of the files setting the parameter, the overriding environment variable, or "default"
+	Env  string   // name of the environment variable overriding the parameter, empty if not overridden
+}
+
+// GetSources returns the configuration files in the search path of a selected notebook in order of precedence, highest
+// first, and the origin of the active value of each configuration parameter. Maps are merged across files and are
+// attributed to every file setting them.
+func GetSources(sel Selection) ([]File, []Source, error) {
+	files, err := GetConfigFiles(sel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileKeys := map[string]map[string]int{}
+	for _, f := range files {
+		if !f.Exists {
+			continue
+		}
+		doc, version, err := readConfigFile(f.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read config file: %w", err)
+		}
+		lines := map[string]int{}
+		if len(doc.Content) > 0 {
+			addLines(doc.Content[0], "", lines)
+		}
+		if version < currentVersion {
+			// the version is set by upgrading the file
+			delete(lines, versionKey)
+		}
+		fileKeys[f.Path] = lines
+	}
+
+	sources := []Source{}
+	for _, p := range getParameters(reflect.TypeOf(Opts{}), "", "") {
+		source := Source{Key: p.key}
+		if _, set := os.LookupEnv(p.env); set && p.env != "" {
+			source.Env = p.env
+			source.From = []string{fmt.Sprintf("environment variable [%s]", p.env)}
+		} else {
+			for _, f := range files {
+				if _, found := fileKeys[f.Path][p.key]; !found {
+					continue
+				}
+				source.From = append(source.From, f.Path)
+				if p.kind != reflect.Map {
+					break
+				}
+			}
+		}
+		if len(source.From) == 0 {
+			source.From = []string{sourceDefault}
+		}
+		sources = append(sources, source)
+	}
+	return files, sources, nil
+}
+
+// parameter is a single configuration parameter
+type parameter struct {
+	key  string
+	env  string
+	kind reflect.Kind
+}
+
+// getParameters lists the parameters of an options type, keyed by dotted yaml names, in the order of the type's fields
+func getParameters(t reflect.Type, keyPrefix string, envPrefix string) []parameter {
+	params := []parameter{}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		name := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := keyPrefix + name
+		if structField.Type.Kind() == reflect.Struct {
+			params = append(params, getParameters(structField.Type, key+".", envPrefix+structField.Tag.Get("env-prefix"))...)
+			continue
+		}
+		env := ""
+		if tag := structField.Tag.Get("env"); tag != "" {
+			env = envPrefix + tag
+		}
+		params = append(params, parameter{key: key, env: env, kind: structField.Type.Kind()})
+	}
+	return params
+}
+
+// getUserConfigDir returns the user's configuration directory, which is $XDG_CONFIG_HOME if set on any platform and
+// otherwise the platform's default, such as ~/.config on Linux and ~/Library/Application Support on macOS
+func getUserConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	return os.UserConfigDir()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
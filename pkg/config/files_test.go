@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// configFiles are the contents of the configuration files in the search path, empty for files that do not exist
+type configFiles struct {
+	flag    string
+	env     string
+	userDir string
+	appDir  string
+}
+
+// setupConfigFiles writes configuration files to a temporary search path and returns the location of the application
+// directory, with the configuration file selected by flag if it is written, and the paths of the files
+func setupConfigFiles(t *testing.T, files configFiles) (location, configFiles) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+	t.Setenv(envConfigFile, "")
+
+	appDir := filepath.Join(tmpDir, "notes")
+	paths := configFiles{
+		flag:    filepath.Join(tmpDir, "flag.yml"),
+		env:     filepath.Join(tmpDir, "env.yml"),
+		userDir: filepath.Join(tmpDir, ".config", registryDirName, userFileName),
+		appDir:  GetConfigFilePath(appDir),
+	}
+	write := func(path string, contents string) {
+		if contents == "" {
+			return
+		}
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+	write(paths.flag, files.flag)
+	write(paths.env, files.env)
+	write(paths.userDir, files.userDir)
+	write(paths.appDir, files.appDir)
+
+	loc := location{appDir: appDir}
+	if files.flag != "" {
+		loc.configFile = paths.flag
+	}
+	if files.env != "" {
+		t.Setenv(envConfigFile, paths.env)
+	}
+	return loc, paths
+}
+
+func TestLoadOptsLayered(t *testing.T) {
+	type testCase struct {
+		files    configFiles
+		env      map[string]string
+		modify   func(*Opts)
+		expected func(*Opts)
+	}
+
+	tests := map[string]testCase{
+		"no configuration files": {
+			expected: func(o *Opts) {},
+		},
+		"application directory": {
+			files: configFiles{
+				appDir: "archive:\n  afterDays: 7\n",
+			},
+			expected: func(o *Opts) { o.Archive.AfterDays = 7 },
+		},
+		"user directory overrides application directory": {
+			files: configFiles{
+				userDir: "archive:\n  afterDays: 7\n",
+				appDir:  "archive:\n  afterDays: 21\n  period: week\n",
+			},
+			expected: func(o *Opts) {
+				o.Archive.AfterDays = 7
+				o.Archive.Period = "week"
+			},
+		},
+		"all files layered by precedence": {
+			files: configFiles{
+				flag:    "header:\n  prefix: flag\n",
+				env:     "header:\n  prefix: env\n  suffix: env\n",
+				userDir: "header:\n  prefix: user\n  suffix: user\n  trailingNewlines: 2\n",
+				appDir:  "header:\n  prefix: app\n  suffix: app\n  trailingNewlines: 3\n  timeFormat: Jan 02 2006\n",
+			},
+			expected: func(o *Opts) {
+				o.Header.Prefix = "flag"
+				o.Header.Suffix = "env"
+				o.Header.TrailingNewlines = 2
+				o.Header.TimeFormat = "Jan 02 2006"
+			},
+		},
+		"lists are replaced": {
+			files: configFiles{
+				userDir: "section:\n  names: [TODO]\n",
+				appDir:  "section:\n  names: [TODO, DONE, IDEAS]\n",
+			},
+			expected: func(o *Opts) { o.Section.Names = []string{"TODO"} },
+		},
+		"maps are merged": {
+			files: configFiles{
+				userDir: "archive:\n  sectionPolicies:\n    DONE: drop\n",
+				appDir:  "archive:\n  sectionPolicies:\n    DONE: keep\n    NOTES: separate\n",
+			},
+			expected: func(o *Opts) { o.Archive.SectionPolicies = map[string]string{"DONE": "drop", "NOTES": "separate"} },
+		},
+		"environment variables override files": {
+			files: configFiles{
+				flag: "archive:\n  afterDays: 7\n",
+			},
+			env:      map[string]string{"TEXTNOTE_ARCHIVE_AFTER_DAYS": "3"},
+			expected: func(o *Opts) { o.Archive.AfterDays = 3 },
+		},
+		"backwards compatible field in lower precedence file": {
+			files: configFiles{
+				userDir: "templateFileCountThresh: 60\n",
+				appDir:  "templateFileCountTresh: 30\n",
+			},
+			expected: func(o *Opts) { o.TemplateFileCountThresh = 60 },
+		},
+		"backwards compatible field in higher precedence file": {
+			files: configFiles{
+				userDir: "templateFileCountTresh: 30\n",
+				appDir:  "templateFileCountThresh: 60\n",
+			},
+			expected: func(o *Opts) { o.TemplateFileCountThresh = 30 },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			loc, _ := setupConfigFiles(t, test.files)
+			for env, value := range test.env {
+				t.Setenv(env, value)
+			}
+
+			opts, err := loadOpts(loc)
+			require.NoError(t, err)
+
+			expected := getDefaultOpts()
+			expected.AppDir = loc.appDir
+			test.expected(&expected)
+			require.Equal(t, expected, opts)
+		})
+	}
+
+	t.Run("selected file must exist", func(t *testing.T) {
+		loc, _ := setupConfigFiles(t, configFiles{})
+		loc.configFile = filepath.Join(loc.appDir, "missing.yml")
+		_, err := loadOpts(loc)
+		require.Error(t, err)
+	})
+}
+
+func TestLoadProblemLocation(t *testing.T) {
+	loc, paths := setupConfigFiles(t, configFiles{appDir: "version: 1\nfile:\n  ext: .txt\n"})
+	t.Setenv(envAppDir, loc.appDir)
+	t.Setenv(envNotebook, "")
+
+	_, err := Load(Selection{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fmt.Sprintf("file.ext (%s line 3): file extension must not include leading dot", paths.appDir))
+}
+
+func TestCreateIfNotExistsWithConfigFile(t *testing.T) {
+	loc, paths := setupConfigFiles(t, configFiles{userDir: "archive:\n  afterDays: 7\n"})
+	require.NoError(t, os.MkdirAll(loc.appDir, 0o755))
+
+	require.NoError(t, CreateIfNotExists(loc.appDir, loc.configFile))
+	require.NoFileExists(t, paths.appDir)
+
+	require.Equal(t, paths.appDir, getTargetConfigFile(loc))
+}
+
+func TestGetSources(t *testing.T) {
+	loc, paths := setupConfigFiles(t, configFiles{
+		flag:    "header:\n  prefix: flag\n",
+		userDir: "header:\n  prefix: user\n  suffix: user\narchive:\n  sectionPolicies:\n    DONE: drop\n",
+		appDir:  "archive:\n  sectionPolicies:\n    NOTES: keep\ntemplateFileCountTresh: 30\n",
+	})
+	t.Setenv(envAppDir, loc.appDir)
+	t.Setenv(envNotebook, "")
+	t.Setenv("TEXTNOTE_HEADER_TIME_FORMAT", "Jan 02 2006")
+
+	files, sources, err := GetSources(Selection{ConfigFile: loc.configFile})
+	require.NoError(t, err)
+	require.Equal(t, []File{
+		{Path: paths.flag, Source: sourceFlag, Exists: true},
+		{Path: paths.userDir, Source: sourceUserDir, Exists: true},
+		{Path: paths.appDir, Source: sourceAppDir, Exists: true},
+	}, files)
+
+	from := map[string][]string{}
+	for _, source := range sources {
+		from[source.Key] = source.From
+	}
+	require.Equal(t, []string{paths.flag}, from["header.prefix"])
+	require.Equal(t, []string{paths.userDir}, from["header.suffix"])
+	require.Equal(t, []string{"environment variable [TEXTNOTE_HEADER_TIME_FORMAT]"}, from["header.timeFormat"])
+	require.Equal(t, []string{paths.userDir, paths.appDir}, from["archive.sectionPolicies"])
+	require.Equal(t, []string{paths.appDir}, from["templateFileCountThresh"])
+	require.Equal(t, []string{sourceDefault}, from["archive.afterDays"])
+	require.Equal(t, []string{sourceDefault}, from["periods.weekly.fileTimeFormat"])
+}
+
+func TestGetUserConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	userDir, err := getUserConfigDir()
+	require.NoError(t, err)
+	require.Equal(t, dir, userDir)
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Get returns the active value of a configuration parameter of a selected notebook identified by a key of dotted yaml
+// names, such as "archive.afterDays", formatted as yaml for parameters that are not a single value
+func Get(sel Selection, key string) (string, error) {
+	opts, err := Load(sel)
+	if err != nil {
+		return "", err
+	}
+	f, err := lookupField(&opts, key)
+	if err != nil {
+		return "", err
+	}
+	return f.format()
+}
+
+// Set sets a configuration parameter of a selected notebook identified by a key of dotted yaml names to a value in the
+// configuration file selected by the --config flag or otherwise the notebook's configuration file, preserving the
+// comments and ordering of the file. The value is parsed as the type of the parameter, as yaml for lists and maps, and
+// the resulting configuration of the files, without environment variable overrides, is validated before the file is
+// written.
+func Set(sel Selection, key string, value string) error {
+	loc, err := sel.locate()
+	if err != nil {
+		return err
+	}
+	// validate the configuration of the files, without environment variable overrides, as the files are what is written
+	opts, err := readOpts(loc, false)
+	if err != nil {
+		return err
+	}
+
+	f, err := lookupField(&opts, key)
+	if err != nil {
+		return err
+	}
+	parsed, err := f.set(value)
+	if err != nil {
+		return err
+	}
+	err = ValidateOpts(opts)
+	if err != nil {
+		return fmt.Errorf("cannot set [%s] to [%s]: %w", key, value, err)
+	}
+
+	configPath := getTargetConfigFile(loc)
+	keys := strings.Split(key, ".")
+	err = setInFile(configPath, keys, parsed)
+	if err != nil {
+		return err
+	}
+	if f.env != "" && os.Getenv(f.env) != "" {
+		log.Printf("environment variable [%s] overrides the value of [%s] set in configuration file [%s]", f.env, key, configPath)
+	}
+	return warnOverridingFiles(loc, configPath, key, keys)
+}
+
+// warnOverridingFiles warns if configuration files of higher precedence than a configuration file set a key
+func warnOverridingFiles(loc location, configPath string, key string, keys []string) error {
+	files, err := getConfigFiles(loc)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.Path == configPath {
+			break
+		}
+		if !f.Exists {
+			continue
+		}
+		doc, _, err := readConfigFile(f.Path)
+		if err != nil {
+			return err
+		}
+		if len(doc.Content) > 0 && lookupNode(doc.Content[0], keys) != nil {
+			log.Printf("configuration file [%s] overrides the value of [%s] set in configuration file [%s]", f.Path, key, configPath)
+		}
+	}
+	return nil
+}
+
+// field is a configuration parameter located by its key
+type field struct {
+	key    string
+	value  reflect.Value // the parameter or, for an entry of a map parameter, the map
+	env    string        // environment variable overriding the parameter
+	mapKey string        // key of the entry of a map parameter, empty for other parameters
+}
+
+// lookupField locates the configuration parameter identified by a key of dotted yaml names
+func lookupField(opts *Opts, key string) (field, error) {
+	f := field{key: key, value: reflect.ValueOf(opts).Elem()}
+	envPrefix := ""
+
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if f.value.Kind() == reflect.Map && i == len(parts)-1 {
+			f.mapKey = part
+			return f, nil
+		}
+		if f.value.Kind() != reflect.Struct {
+			return f, fmt.Errorf("unknown configuration key [%s]", key)
+		}
+
+		found := false
+		for j := 0; j < f.value.NumField(); j++ {
+			structField := f.value.Type().Field(j)
+			name := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" || name != part {
+				continue
+			}
+			envPrefix += structField.Tag.Get("env-prefix")
+			if env := structField.Tag.Get("env"); env != "" {
+				f.env = envPrefix + env
+			}
+			f.value = f.value.Field(j)
+			found = true
+			break
+		}
+		if !found {
+			return f, fmt.Errorf("unknown configuration key [%s]", key)
+		}
+	}
+	return f, nil
+}
+
+// format formats the value of a field as a plain string for a single value and as yaml otherwise
+func (f field) format() (string, error) {
+	value := f.value
+	if f.mapKey != "" {
+		value = f.value.MapIndex(reflect.ValueOf(f.mapKey))
+		if !value.IsValid() {
+			return "", nil
+		}
+	}
+	switch value.Kind() {
+	case reflect.String, reflect.Int, reflect.Bool:
+		return fmt.Sprint(value.Interface()), nil
+	}
+	yml, err := yaml.Marshal(value.Interface())
+	if err != nil {
+		return "", fmt.Errorf("unable to format [%s]: %w", f.key, err)
+	}
+	return strings.TrimSuffix(string(yml), "\n"), nil
+}
+
+// set parses a value as the type of a field and sets the field, returning the parsed value
+func (f field) set(value string) (any, error) {
+	if f.mapKey != "" {
+		if f.value.IsNil() {
+			f.value.Set(reflect.MakeMap(f.value.Type()))
+		}
+		parsed, err := parseValue(f.key, f.value.Type().Elem(), value)
+		if err != nil {
+			return nil, err
+		}
+		f.value.SetMapIndex(reflect.ValueOf(f.mapKey), parsed)
+		return parsed.Interface(), nil
+	}
+
+	if f.key == versionKey {
+		return nil, fmt.Errorf("configuration key [%s] is set by upgrading the configuration file with config update", f.key)
+	}
+	if f.value.Kind() == reflect.Struct {
+		return nil, fmt.Errorf("configuration key [%s] is a group of parameters, set one of its parameters instead", f.key)
+	}
+	parsed, err := parseValue(f.key, f.value.Type(), value)
+	if err != nil {
+		return nil, err
+	}
+	f.value.Set(parsed)
+	return parsed.Interface(), nil
+}
+
+// parseValue parses a value as a type, with strings taken literally and all other types parsed as yaml
+func parseValue(key string, t reflect.Type, value string) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	if t.Kind() == reflect.String {
+		ptr.Elem().SetString(value)
+		return ptr.Elem(), nil
+	}
+	err := yaml.Unmarshal([]byte(value), ptr.Interface())
+	if err != nil {
+		return ptr.Elem(), fmt.Errorf("value [%s] of [%s] must be of type %s", value, key, describeType(t))
+	}
+	return ptr.Elem(), nil
+}
+
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Slice:
+		return "list, such as [a, b]"
+	case reflect.Map:
+		return "map, such as {a: b}"
+	}
+	return t.Kind().String()
+}
+
+// setInFile sets the value of a key of yaml names in a yaml file, preserving the comments and ordering of the file. A
+// file of a previous version is upgraded to the current version, keeping a backup of the file.
+func setInFile(path string, keys []string, value any) error {
+	raw, doc, _, err := upgradeConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		*doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+		setVersion(doc.Content[0], currentVersion)
+	}
+
+	node := doc.Content[0]
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+
+		_, child := findKey(node, key)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+
+		if i == len(keys)-1 {
+			encoded := yaml.Node{}
+			err := encoded.Encode(value)
+			if err != nil {
+				return fmt.Errorf("unable to encode value: %w", err)
+			}
+			encoded.HeadComment = child.HeadComment
+			encoded.LineComment = child.LineComment
+			encoded.FootComment = child.FootComment
+			*child = encoded
+		}
+		node = child
+	}
+
+	return writeConfigFile(path, doc, detectIndent(raw))
+}
+
+// detectIndent returns the indentation of the first indented line of yaml, defaulting to the indentation used when
+// writing a yaml configuration file
+func detectIndent(raw []byte) int {
+	for _, line := range strings.Split(string(raw), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "- ") {
+			continue
+		}
+		return len(line) - len(trimmed)
+	}
+	return 4
+}
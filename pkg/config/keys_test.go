@@ -0,0 +1,301 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldFormat(t *testing.T) {
+	opts := getTestOpts()
+	opts.Archive.SectionPolicies = map[string]string{"DONE": "drop"}
+
+	type testCase struct {
+		key      string
+		expected string
+	}
+
+	tests := map[string]testCase{
+		"string": {
+			key:      "archive.filePrefix",
+			expected: "archive-",
+		},
+		"int": {
+			key:      "archive.afterDays",
+			expected: "14",
+		},
+		"bool": {
+			key:      "archive.tableOfContents",
+			expected: "false",
+		},
+		"list": {
+			key:      "section.names",
+			expected: "- TODO\n- DONE\n- NOTES",
+		},
+		"map entry": {
+			key:      "archive.sectionPolicies.DONE",
+			expected: "drop",
+		},
+		"unset map entry": {
+			key:      "archive.sectionPolicies.NOTES",
+			expected: "",
+		},
+		"nested period": {
+			key:      "periods.weekly.fileTimeFormat",
+			expected: opts.Periods.Weekly.FileTimeFormat,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := lookupField(&opts, test.key)
+			require.NoError(t, err)
+			formatted, err := f.format()
+			require.NoError(t, err)
+			require.Equal(t, test.expected, formatted)
+		})
+	}
+}
+
+func TestFieldSet(t *testing.T) {
+	type testCase struct {
+		key      string
+		value    string
+		check    func(Opts) any
+		expected any
+		env      string
+		err      bool
+	}
+
+	tests := map[string]testCase{
+		"unknown key": {
+			key:   "archive.unknown",
+			value: "1",
+			err:   true,
+		},
+		"key below a parameter": {
+			key:   "archive.afterDays.days",
+			value: "1",
+			err:   true,
+		},
+		"version": {
+			key:   "version",
+			value: "2",
+			err:   true,
+		},
+		"key excluded from configuration": {
+			key:   "AppDir",
+			value: "dir",
+			err:   true,
+		},
+		"group of parameters": {
+			key:   "archive",
+			value: "{afterDays: 1}",
+			err:   true,
+		},
+		"int": {
+			key:      "archive.afterDays",
+			value:    "30",
+			check:    func(o Opts) any { return o.Archive.AfterDays },
+			expected: 30,
+			env:      "TEXTNOTE_ARCHIVE_AFTER_DAYS",
+		},
+		"invalid int": {
+			key:   "archive.afterDays",
+			value: "thirty",
+			err:   true,
+		},
+		"string is taken literally": {
+			key:      "header.timeFormat",
+			value:    "[Mon] 02 Jan 2006",
+			check:    func(o Opts) any { return o.Header.TimeFormat },
+			expected: "[Mon] 02 Jan 2006",
+			env:      "TEXTNOTE_HEADER_TIME_FORMAT",
+		},
+		"bool": {
+			key:      "archive.tableOfContents",
+			value:    "true",
+			check:    func(o Opts) any { return o.Archive.TableOfContents },
+			expected: true,
+			env:      "TEXTNOTE_ARCHIVE_TABLE_OF_CONTENTS",
+		},
+		"list": {
+			key:      "section.names",
+			value:    "[TODO, IDEAS]",
+			check:    func(o Opts) any { return o.Section.Names },
+			expected: []string{"TODO", "IDEAS"},
+			env:      "TEXTNOTE_SECTION_NAMES",
+		},
+		"invalid list": {
+			key:   "section.names",
+			value: "{TODO: IDEAS}",
+			err:   true,
+		},
+		"map entry": {
+			key:      "archive.sectionPolicies.DONE",
+			value:    "drop",
+			check:    func(o Opts) any { return o.Archive.SectionPolicies },
+			expected: map[string]string{"DONE": "drop"},
+			env:      "TEXTNOTE_ARCHIVE_SECTION_POLICIES",
+		},
+		"environment variable with prefix": {
+			key:      "periods.monthly.fileTimeFormat",
+			value:    "Jan-2006",
+			check:    func(o Opts) any { return o.Periods.Monthly.FileTimeFormat },
+			expected: "Jan-2006",
+			env:      "TEXTNOTE_PERIODS_MONTHLY_FILE_TIME_FORMAT",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := getTestOpts()
+			f, err := lookupField(&opts, test.key)
+			if err == nil {
+				_, err = f.set(test.value)
+			}
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, test.check(opts))
+			require.Equal(t, test.env, f.env)
+		})
+	}
+}
+
+func TestSetInFile(t *testing.T) {
+	type testCase struct {
+		contents string
+		keys     []string
+		value    any
+		expected string
+	}
+
+	tests := map[string]testCase{
+		"missing file": {
+			keys:     []string{"archive", "afterDays"},
+			value:    30,
+			expected: "version: 1\narchive:\n    afterDays: 30\n",
+		},
+		"comments, ordering, and indentation are preserved": {
+			contents: `# notes configuration
+section:
+  # sections of each note
+  names:
+    - TODO
+    - DONE
+archive:
+  afterDays: 14 # two weeks
+  filePrefix: archive-
+`,
+			keys:  []string{"archive", "afterDays"},
+			value: 30,
+			expected: `# notes configuration
+version: 1
+section:
+  # sections of each note
+  names:
+    - TODO
+    - DONE
+archive:
+  afterDays: 30 # two weeks
+  filePrefix: archive-
+`,
+		},
+		"list is replaced": {
+			contents: "version: 1\nsection:\n    names:\n        - TODO\n",
+			keys:     []string{"section", "names"},
+			value:    []string{"TODO", "IDEAS"},
+			expected: "version: 1\nsection:\n    names:\n        - TODO\n        - IDEAS\n",
+		},
+		"missing keys are appended": {
+			contents: "version: 1\nfile:\n    ext: txt\n",
+			keys:     []string{"archive", "sectionPolicies", "DONE"},
+			value:    "drop",
+			expected: "version: 1\nfile:\n    ext: txt\narchive:\n    sectionPolicies:\n        DONE: drop\n",
+		},
+		"empty map is filled": {
+			contents: "version: 1\narchive:\n    sectionPolicies: {}\n",
+			keys:     []string{"archive", "sectionPolicies", "DONE"},
+			value:    "drop",
+			expected: "version: 1\narchive:\n    sectionPolicies: {DONE: drop}\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".config.yml")
+			if test.contents != "" {
+				require.NoError(t, os.WriteFile(path, []byte(test.contents), 0o644))
+			}
+			require.NoError(t, setInFile(path, test.keys, test.value))
+			written, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(written))
+		})
+	}
+}
+
+func TestSetTargetFile(t *testing.T) {
+	userContents := "version: 1\neditor:\n    command: hx {file}:{line}\n"
+
+	t.Run("notebook file", func(t *testing.T) {
+		loc, paths := setupConfigFiles(t, configFiles{userDir: userContents})
+		require.NoError(t, os.MkdirAll(loc.appDir, 0o755))
+		t.Setenv(envAppDir, loc.appDir)
+		t.Setenv(envNotebook, "")
+
+		require.NoError(t, Set(Selection{}, "archive.afterDays", "21"))
+
+		raw, err := os.ReadFile(paths.userDir)
+		require.NoError(t, err)
+		require.Equal(t, userContents, string(raw))
+		raw, err = os.ReadFile(paths.appDir)
+		require.NoError(t, err)
+		require.Equal(t, "version: 1\narchive:\n    afterDays: 21\n", string(raw))
+	})
+
+	t.Run("invalid environment variable does not block a valid value", func(t *testing.T) {
+		loc, paths := setupConfigFiles(t, configFiles{appDir: "version: 1\n"})
+		t.Setenv(envAppDir, loc.appDir)
+		t.Setenv(envNotebook, "")
+		t.Setenv("TEXTNOTE_ARCHIVE_AFTER_DAYS", "-1")
+
+		require.NoError(t, Set(Selection{}, "archive.period", "week"))
+
+		raw, err := os.ReadFile(paths.appDir)
+		require.NoError(t, err)
+		require.Equal(t, "version: 1\narchive:\n    period: week\n", string(raw))
+	})
+
+	t.Run("environment variable does not mask an invalid file", func(t *testing.T) {
+		contents := "version: 1\narchive:\n    afterDays: -1\n"
+		loc, paths := setupConfigFiles(t, configFiles{appDir: contents})
+		t.Setenv(envAppDir, loc.appDir)
+		t.Setenv(envNotebook, "")
+		t.Setenv("TEXTNOTE_ARCHIVE_AFTER_DAYS", "7")
+
+		require.ErrorContains(t, Set(Selection{}, "archive.period", "week"), "archive.afterDays")
+
+		raw, err := os.ReadFile(paths.appDir)
+		require.NoError(t, err)
+		require.Equal(t, contents, string(raw))
+	})
+
+	t.Run("file selected by flag", func(t *testing.T) {
+		loc, paths := setupConfigFiles(t, configFiles{flag: "version: 1\n", userDir: userContents})
+		t.Setenv(envAppDir, loc.appDir)
+		t.Setenv(envNotebook, "")
+
+		require.NoError(t, Set(Selection{ConfigFile: loc.configFile}, "archive.afterDays", "21"))
+
+		raw, err := os.ReadFile(paths.flag)
+		require.NoError(t, err)
+		require.Equal(t, "version: 1\narchive:\n    afterDays: 21\n", string(raw))
+		require.NoFileExists(t, paths.appDir)
+	})
+}
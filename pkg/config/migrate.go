@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// versionKey is the key of the version of a configuration file
+const versionKey = "version"
+
+// migration upgrades a configuration file from one version to the next
+type migration struct {
+	description string
+	migrate     func(root *yaml.Node) error
+}
+
+// migrations upgrade configuration files from the version of their index to the next version, so the current version
+// is the number of migrations. Configuration files without a version are version 0. A change to the yaml names of
+// configuration parameters is shipped by appending a migration.
+var migrations = []migration{
+	{
+		description: "rename misspelled templateFileCountTresh to templateFileCountThresh",
+		migrate:     renameKey("templateFileCountTresh", "templateFileCountThresh"),
+	},
+}
+
+// currentVersion is the version of configuration files written by the application
+var currentVersion = len(migrations)
+
+// readConfigFile reads a configuration file and upgrades it to the current version, returning the upgraded document
+// and the version of the file
+func readConfigFile(path string) (*yaml.Node, int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc := &yaml.Node{}
+	err = yaml.Unmarshal(raw, doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to parse [%s]: %w", path, err)
+	}
+	version, err := migrate(doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to upgrade [%s]: %w", path, err)
+	}
+	return doc, version, nil
+}
+
+// upgradeConfigFile reads a configuration file, which need not exist, and upgrades it to the current version, keeping a
+// backup of a file of a previous version. It returns the contents of the file, the upgraded document, and whether the
+// document was upgraded.
+func upgradeConfigFile(path string) ([]byte, *yaml.Node, bool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, false, fmt.Errorf("unable to read configuration file [%s]: %w", path, err)
+	}
+	doc := &yaml.Node{}
+	err = yaml.Unmarshal(raw, doc)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("unable to parse configuration file [%s]: %w", path, err)
+	}
+	version, err := migrate(doc)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("unable to upgrade configuration file [%s]: %w", path, err)
+	}
+	if version == currentVersion || len(doc.Content) == 0 {
+		return raw, doc, false, nil
+	}
+	backup, err := backupFile(path)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	log.Printf("upgraded configuration file [%s] from version [%d] to [%d], backed up to [%s]", path, version, currentVersion, backup)
+	return raw, doc, true, nil
+}
+
+// writeConfigFile writes a yaml document to a configuration file with an indentation
+func writeConfigFile(path string, doc *yaml.Node, indent int) error {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(indent)
+	err := enc.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("unable to write configuration file [%s]: %w", path, err)
+	}
+	err = enc.Close()
+	if err != nil {
+		return fmt.Errorf("unable to write configuration file [%s]: %w", path, err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// migrate upgrades a yaml document of a configuration file to the current version in place, returning the version of
+// the document before the upgrade
+func migrate(doc *yaml.Node) (int, error) {
+	if len(doc.Content) == 0 {
+		// an empty document has nothing to upgrade
+		return currentVersion, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return 0, fmt.Errorf("configuration must be a map")
+	}
+
+	version := 0
+	if _, node := findKey(root, versionKey); node != nil {
+		v, err := strconv.Atoi(node.Value)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid configuration version [%s]", node.Value)
+		}
+		version = v
+	}
+	if version > currentVersion {
+		return version, fmt.Errorf("configuration version [%d] is newer than the supported version [%d], upgrade textnote to use it", version, currentVersion)
+	}
+
+	for v := version; v < currentVersion; v++ {
+		err := migrations[v].migrate(root)
+		if err != nil {
+			return version, fmt.Errorf("unable to %s: %w", migrations[v].description, err)
+		}
+	}
+	setVersion(root, currentVersion)
+	return version, nil
+}
+
+// setVersion sets the version of the root mapping of a configuration file, adding the version as the first key below
+// any comment heading the file if it is not set
+func setVersion(root *yaml.Node, version int) {
+	value := strconv.Itoa(version)
+	if _, node := findKey(root, versionKey); node != nil {
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!int", value
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: versionKey}
+	if len(root.Content) > 0 {
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{key, {Kind: yaml.ScalarNode, Tag: "!!int", Value: value}}, root.Content...)
+}
+
+// renameKey returns a migration renaming a key of dotted yaml names to a key with the same parent, dropping the key if
+// its replacement is already set
+func renameKey(from string, to string) func(root *yaml.Node) error {
+	return func(root *yaml.Node) error {
+		keys := strings.Split(from, ".")
+		parent := lookupNode(root, keys[:len(keys)-1])
+		if parent == nil || parent.Kind != yaml.MappingNode {
+			return nil
+		}
+		i, node := findKey(parent, keys[len(keys)-1])
+		if node == nil {
+			return nil
+		}
+		if _, replacement := findKey(parent, to); replacement != nil {
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+			return nil
+		}
+		parent.Content[i].Value = to
+		return nil
+	}
+}
+
+// findKey returns the index of a key in a mapping node and the key's value node, which is nil if the key is not found
+func findKey(node *yaml.Node, key string) (int, *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i, node.Content[i+1]
+		}
+	}
+	return -1, nil
+}
+
+// lookupNode returns the value node of a key of yaml names in a mapping node, or nil if the key is not found
+func lookupNode(node *yaml.Node, keys []string) *yaml.Node {
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		_, node = findKey(node, key)
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// backupFile copies a file to a backup file of the same name with a .bak extension, returning the path of the backup
+func backupFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read [%s] for backup: %w", path, err)
+	}
+	backup := path + ".bak"
+	err = os.WriteFile(backup, raw, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("unable to write backup [%s]: %w", backup, err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrate(t *testing.T) {
+	type testCase struct {
+		contents        string
+		expected        string
+		expectedVersion int
+		shouldErr       bool
+	}
+
+	tests := map[string]testCase{
+		"empty": {
+			contents:        "",
+			expected:        "",
+			expectedVersion: currentVersion,
+		},
+		"without version": {
+			contents:        "# my settings\nheader:\n    prefix: '>'\ntemplateFileCountTresh: 30 # typo\n",
+			expected:        "# my settings\nversion: 1\nheader:\n    prefix: '>'\ntemplateFileCountThresh: 30 # typo\n",
+			expectedVersion: 0,
+		},
+		"misspelled field with replacement": {
+			contents:        "templateFileCountThresh: 60\ntemplateFileCountTresh: 30\n",
+			expected:        "version: 1\ntemplateFileCountThresh: 60\n",
+			expectedVersion: 0,
+		},
+		"current version": {
+			contents:        "header:\n    prefix: '>'\nversion: 1\n",
+			expected:        "header:\n    prefix: '>'\nversion: 1\n",
+			expectedVersion: 1,
+		},
+		"newer version": {
+			contents:  "version: 2\n",
+			shouldErr: true,
+		},
+		"invalid version": {
+			contents:  "version: latest\n",
+			shouldErr: true,
+		},
+		"not a map": {
+			contents:  "- header\n",
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc := yaml.Node{}
+			require.NoError(t, yaml.Unmarshal([]byte(test.contents), &doc))
+
+			version, err := migrate(&doc)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedVersion, version)
+
+			if len(doc.Content) == 0 {
+				require.Empty(t, test.expected)
+				return
+			}
+			migrated, err := yaml.Marshal(&doc)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(migrated))
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	userContents := "# shared settings\neditor:\n  command: hx {file}:{line}\n"
+	appContents := "# notebook settings\ntemplateFileCountTresh: 30 # warn early\n"
+	loc, paths := setupConfigFiles(t, configFiles{userDir: userContents, appDir: appContents})
+	t.Setenv(envAppDir, loc.appDir)
+	t.Setenv(envNotebook, "")
+	t.Setenv("TEXTNOTE_ARCHIVE_FILE_PREFIX", "my_archive-")
+
+	require.NoError(t, Update(Selection{}))
+
+	// the notebook's file is overwritten with the active configuration at the current version
+	expected := getDefaultOpts()
+	expected.AppDir = loc.appDir
+	expected.TemplateFileCountThresh = 30
+	expected.Editor.Command = "hx {file}:{line}"
+	expected.Archive.FilePrefix = "my_archive-"
+	expected.Section.Items = []SectionItem{}
+	raw, err := os.ReadFile(paths.appDir)
+	require.NoError(t, err)
+	updated := Opts{}
+	require.NoError(t, yaml.Unmarshal(raw, &updated))
+	updated.AppDir = loc.appDir
+	require.Equal(t, expected, updated)
+
+	backup, err := os.ReadFile(paths.appDir + ".bak")
+	require.NoError(t, err)
+	require.Equal(t, appContents, string(backup))
+
+	// other files are upgraded in place without adding the active configuration
+	raw, err = os.ReadFile(paths.userDir)
+	require.NoError(t, err)
+	require.Equal(t, "# shared settings\nversion: 1\neditor:\n  command: hx {file}:{line}\n", string(raw))
+	backup, err = os.ReadFile(paths.userDir + ".bak")
+	require.NoError(t, err)
+	require.Equal(t, userContents, string(backup))
+
+	// updating again leaves the upgraded files unchanged
+	require.NoError(t, os.Remove(paths.userDir+".bak"))
+	require.NoError(t, Update(Selection{}))
+	require.NoFileExists(t, paths.userDir+".bak")
+}
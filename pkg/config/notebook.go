@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// envNotebook is the name of the environment variable specifying the notebook to use
+	envNotebook = "TEXTNOTE_NOTEBOOK"
+	// registryDirName is the name of the directory within the user's configuration directory holding the notebook registry
+	// and the user's configuration file
+	registryDirName = "textnote"
+	// registryFileName is the name of the notebook registry file
+	registryFileName = "notebooks.yml"
+)
+
+// notebookRegistry maps the names of notebooks to their application directories
+type notebookRegistry struct {
+	Default   string            `yaml:"default"`
+	Notebooks map[string]string `yaml:"notebooks"`
+}
+
+// GetAppDir resolves the application directory for a notebook
+//
+// The notebook is selected by name or, if the name is empty, by the TEXTNOTE_NOTEBOOK environment variable.
+// If no notebook is selected, the application directory is read from the TEXTNOTE_DIR environment variable,
+// falling back on the default notebook of the registry.
+func GetAppDir(notebook string) (string, error) {
+	if notebook == "" {
+		notebook = os.Getenv(envNotebook)
+	}
+	if notebook == "" {
+		if dir := os.Getenv(envAppDir); dir != "" {
+			return dir, nil
+		}
+	}
+
+	registry, err := readNotebookRegistry()
+	if err != nil {
+		return "", err
+	}
+
+	if notebook == "" {
+		notebook = registry.Default
+	}
+	if notebook == "" {
+		return "", fmt.Errorf("required environment variable [%s] is not set and no default notebook is registered", envAppDir)
+	}
+
+	dir, found := registry.Notebooks[notebook]
+	if !found || dir == "" {
+		return "", fmt.Errorf("notebook [%s] not found in registry [%s]", notebook, getNotebookRegistryPath())
+	}
+	return expandHome(dir), nil
+}
+
+func readNotebookRegistry() (notebookRegistry, error) {
+	registry := notebookRegistry{
+		Notebooks: map[string]string{},
+	}
+
+	path := getNotebookRegistryPath()
+	if path == "" {
+		return registry, nil
+	}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		// no registry has been created
+		return registry, nil
+	}
+	if err != nil {
+		return registry, fmt.Errorf("unable to read notebook registry [%s]: %w", path, err)
+	}
+	err = yaml.Unmarshal(raw, &registry)
+	if err != nil {
+		return registry, fmt.Errorf("unable to parse notebook registry [%s]: %w", path, err)
+	}
+	return registry, nil
+}
+
+// getNotebookRegistryPath constructs the full path to the notebook registry file, returning an empty string if
+// the user's configuration directory cannot be determined
+func getNotebookRegistryPath() string {
+	dir, err := getUserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, registryDirName, registryFileName)
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAppDir(t *testing.T) {
+	type testCase struct {
+		registry    string
+		notebook    string
+		envNotebook string
+		envAppDir   string
+		expected    string
+		shouldErr   bool
+	}
+
+	registry := `default: personal
+notebooks:
+  personal: /path/to/personal
+  work: /path/to/work
+`
+
+	tests := map[string]testCase{
+		"notebook from argument": {
+			registry:    registry,
+			notebook:    "work",
+			envNotebook: "personal",
+			envAppDir:   "/path/to/dir",
+			expected:    "/path/to/work",
+		},
+		"notebook from environment": {
+			registry:    registry,
+			envNotebook: "work",
+			envAppDir:   "/path/to/dir",
+			expected:    "/path/to/work",
+		},
+		"application directory from environment": {
+			registry:  registry,
+			envAppDir: "/path/to/dir",
+			expected:  "/path/to/dir",
+		},
+		"default notebook": {
+			registry: registry,
+			expected: "/path/to/personal",
+		},
+		"application directory from environment without registry": {
+			envAppDir: "/path/to/dir",
+			expected:  "/path/to/dir",
+		},
+		"unregistered notebook": {
+			registry:  registry,
+			notebook:  "foo",
+			envAppDir: "/path/to/dir",
+			shouldErr: true,
+		},
+		"notebook without registry": {
+			notebook:  "work",
+			envAppDir: "/path/to/dir",
+			shouldErr: true,
+		},
+		"no notebook or application directory without default": {
+			registry: `notebooks:
+  work: /path/to/work
+`,
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// setup
+			tmpDir := t.TempDir()
+			t.Setenv("HOME", tmpDir)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+			t.Setenv(envNotebook, test.envNotebook)
+			t.Setenv(envAppDir, test.envAppDir)
+			if test.registry != "" {
+				path := getNotebookRegistryPath()
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(test.registry), 0o644))
+			}
+
+			// test
+			appDir, err := GetAppDir(test.notebook)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, appDir)
+		})
+	}
+}
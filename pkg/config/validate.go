@@ -0,0 +1,309 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a misconfiguration identified by the YAML path of the misconfigured option
+type Problem struct {
+	Path    string // YAML path of the option, e.g. "archive.period"
+	File    string // configuration file setting the option, empty if unknown
+	Line    int    // line of the option in the configuration file, zero if the option is not set in the file
+	Message string
+}
+
+// String formats a Problem with its path and, if known, its file and line in the configuration file
+func (p Problem) String() string {
+	if p.Line > 0 && p.File != "" {
+		return fmt.Sprintf("%s (%s line %d): %s", p.Path, p.File, p.Line, p.Message)
+	}
+	if p.Line > 0 {
+		return fmt.Sprintf("%s (line %d): %s", p.Path, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// Problems are all misconfigurations found by validation
+type Problems []Problem
+
+// Error joins all problems, each with its path and, if known, its file and line, into a single error message
+func (p Problems) Error() string {
+	msgs := []string{}
+	for _, problem := range p {
+		msgs = append(msgs, problem.String())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (p *Problems) add(path string, format string, args ...any) {
+	*p = append(*p, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// locate sets the file and line of each problem not yet located that is set in a configuration file
+func (p Problems) locate(file string, lines map[string]int) {
+	for i, problem := range p {
+		if line, found := lines[problem.Path]; found && problem.Line == 0 {
+			p[i].File = file
+			p[i].Line = line
+		}
+	}
+}
+
+func (p *Problems) addErr(path string, err error) {
+	if err != nil {
+		p.add(path, "%s", err)
+	}
+}
+
+// Diagnose loads the configuration of a selected notebook without validation and returns all problems found by
+// Validate, located by file and line in the configuration file of highest precedence setting each misconfigured option
+func Diagnose(sel Selection) (Problems, error) {
+	loc, err := sel.locate()
+	if err != nil {
+		return nil, err
+	}
+	opts, err := loadOpts(loc)
+	if err != nil {
+		return nil, err
+	}
+	files, err := getConfigFiles(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := findProblems(opts)
+	err = problems.locateInFiles(files)
+	return problems, err
+}
+
+// locateInFiles sets the file and line of each problem in the configuration file of highest precedence setting the
+// misconfigured option, given the configuration files in order of precedence, highest first
+func (p Problems) locateInFiles(files []File) error {
+	for _, f := range files {
+		if !f.Exists {
+			continue
+		}
+		doc, _, err := readConfigFile(f.Path)
+		if err != nil {
+			return fmt.Errorf("unable to read config file: %w", err)
+		}
+		lines := map[string]int{}
+		if len(doc.Content) > 0 {
+			addLines(doc.Content[0], "", lines)
+		}
+		p.locate(f.Path, lines)
+	}
+	return nil
+}
+
+// Validate returns all problems found in the specified options, including the problems that do not prevent the
+// application from loading the options: time formats that do not round-trip through formatting and parsing, section
+// prefixes and suffixes that match text other than section names, and archive file names that collide with note file
+// names. Problems are located by line in the contents of the configuration file, which can be empty.
+func Validate(opts Opts, configFile []byte) (Problems, error) {
+	problems := findProblems(opts)
+	lines, err := getLines(configFile)
+	if err != nil {
+		return problems, fmt.Errorf("unable to parse config file: %w", err)
+	}
+	problems.locate("", lines)
+	return problems, nil
+}
+
+func findProblems(opts Opts) Problems {
+	problems := validateOpts(opts)
+	problems = append(problems, validateTimeFormats(opts)...)
+	problems = append(problems, validateSectionPattern(opts)...)
+	problems = append(problems, validateArchiveFileNames(opts)...)
+	return problems
+}
+
+// sampleDays are the dates used to check that formats round-trip, which are the first day of an ISO week to be
+// valid for all note periods
+var sampleDays = []time.Time{
+	time.Date(2021, 11, 22, 0, 0, 0, 0, time.UTC),
+	time.Date(2009, 2, 2, 0, 0, 0, 0, time.UTC),
+}
+
+// sampleMonths are the dates used to check that month formats round-trip
+var sampleMonths = []time.Time{
+	time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2009, 2, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// validateTimeFormats returns problems for time formats that do not produce the same date when a formatted date is
+// parsed, which is needed for dates to be recovered from file names, headers, archive contents, and CLI flags
+func validateTimeFormats(opts Opts) Problems {
+	problems := Problems{}
+
+	dayFormats := []struct {
+		path   string
+		format string
+	}{
+		{"header.timeFormat", opts.Header.TimeFormat},
+		{"file.timeFormat", opts.File.TimeFormat},
+		{"archive.sectionContentTimeFormat", opts.Archive.SectionContentTimeFormat},
+		{"cli.timeFormat", opts.Cli.TimeFormat},
+		{"periods.weekly.fileTimeFormat", opts.Periods.Weekly.FileTimeFormat},
+		{"periods.weekly.headerTimeFormat", opts.Periods.Weekly.HeaderTimeFormat},
+	}
+	for _, f := range dayFormats {
+		problems.addErr(f.path, checkRoundTrip(f.format, sampleDays))
+	}
+
+	monthFormats := []struct {
+		path   string
+		format string
+	}{
+		{"archive.monthTimeFormat", opts.Archive.MonthTimeFormat},
+		{"periods.monthly.fileTimeFormat", opts.Periods.Monthly.FileTimeFormat},
+		{"periods.monthly.headerTimeFormat", opts.Periods.Monthly.HeaderTimeFormat},
+	}
+	for _, f := range monthFormats {
+		problems.addErr(f.path, checkRoundTrip(f.format, sampleMonths))
+	}
+
+	return problems
+}
+
+func checkRoundTrip(format string, dates []time.Time) error {
+	for _, date := range dates {
+		formatted := date.Format(format)
+		parsed, err := time.Parse(format, formatted)
+		if err != nil {
+			return fmt.Errorf("time format [%s] cannot parse its own output [%s]: %w", format, formatted, err)
+		}
+		if !parsed.Equal(date) {
+			return fmt.Errorf("time format [%s] does not round-trip: [%s] is parsed as [%s]", format, formatted, parsed.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// validateSectionPattern returns problems for section prefixes and suffixes that, as used to find section names in
+// the body of a note, do not match every section name or match other text of notes and archives
+func validateSectionPattern(opts Opts) Problems {
+	problems := Problems{}
+
+	// the pattern matches the pattern used to find section names when loading a note
+	pattern := fmt.Sprintf("%s.*%s", opts.Section.Prefix, opts.Section.Suffix)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		problems.add("section.prefix", "section prefix [%s] and suffix [%s] do not form a valid pattern: %s", opts.Section.Prefix, opts.Section.Suffix, err)
+		return problems
+	}
+
+	names := append(append(append([]string{}, opts.Section.Names...), opts.Periods.Weekly.Sections...), opts.Periods.Monthly.Sections...)
+	for _, name := range names {
+		line := opts.Section.Prefix + name + opts.Section.Suffix
+		if re.FindString(line) != line {
+			problems.add("section.prefix", "section prefix [%s] and suffix [%s] do not match section [%s]", opts.Section.Prefix, opts.Section.Suffix, name)
+		}
+	}
+
+	date := sampleDays[0]
+	body := []string{
+		"",
+		"text",
+		opts.Header.Prefix + date.Format(opts.Header.TimeFormat) + opts.Header.Suffix,
+		opts.Header.Prefix + date.Format(opts.Periods.Weekly.HeaderTimeFormat) + opts.Header.Suffix,
+		opts.Header.Prefix + date.Format(opts.Periods.Monthly.HeaderTimeFormat) + opts.Header.Suffix,
+		opts.Archive.HeaderPrefix + date.Format(opts.Archive.MonthTimeFormat) + opts.Archive.HeaderSuffix,
+		opts.Archive.SectionContentPrefix + date.Format(opts.Archive.SectionContentTimeFormat) + opts.Archive.SectionContentSuffix,
+	}
+	for _, text := range body {
+		if re.MatchString(text) {
+			problems.add("section.prefix", "section prefix [%s] and suffix [%s] match [%s], which is not a section name", opts.Section.Prefix, opts.Section.Suffix, text)
+		}
+	}
+
+	return problems
+}
+
+// validateArchiveFileNames returns problems for an archive file prefix with which note file names can begin and
+// for archive file names that can be parsed as dated note file names
+func validateArchiveFileNames(opts Opts) Problems {
+	problems := Problems{}
+	prefix := opts.Archive.FilePrefix
+	if strings.TrimSpace(prefix) == "" {
+		// reported as an empty prefix
+		return problems
+	}
+
+	fileFormats := []string{opts.File.TimeFormat, opts.Periods.Weekly.FileTimeFormat, opts.Periods.Monthly.FileTimeFormat}
+	for _, format := range fileFormats {
+		for _, date := range sampleDays {
+			if name := date.Format(format); strings.HasPrefix(name, prefix) {
+				problems.add("archive.filePrefix", "archive file prefix [%s] collides with note file name [%s]", prefix, name)
+				break
+			}
+		}
+	}
+
+	date := sampleDays[0]
+	year, week := date.ISOWeek()
+	labels := []string{
+		date.Format(opts.Archive.MonthTimeFormat),
+		fmt.Sprintf("%d-W%02d", year, week),
+		fmt.Sprintf("%d-Q%d", date.Year(), (int(date.Month())-1)/3+1),
+		date.Format("2006"),
+	}
+	for _, label := range labels {
+		name := prefix + label
+		for _, format := range fileFormats {
+			if _, err := time.Parse(format, name); err == nil {
+				problems.add("archive.filePrefix", "archive file name [%s] can be parsed as a note file name with time format [%s]", name, format)
+			}
+		}
+	}
+
+	return problems
+}
+
+// getLines returns the line of each option set in the contents of a configuration file, keyed by YAML path
+func getLines(configFile []byte) (map[string]int, error) {
+	lines := map[string]int{}
+	if len(bytes.TrimSpace(configFile)) == 0 {
+		return lines, nil
+	}
+	root := yaml.Node{}
+	err := yaml.Unmarshal(configFile, &root)
+	if err != nil {
+		return lines, err
+	}
+	if len(root.Content) > 0 {
+		addLines(root.Content[0], "", lines)
+	}
+	return lines, nil
+}
+
+func addLines(node *yaml.Node, path string, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keyPath := key.Value
+		if path != "" {
+			keyPath = path + "." + key.Value
+		}
+		lines[keyPath] = key.Line
+		addLines(value, keyPath, lines)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
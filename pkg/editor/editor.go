@@ -1,22 +1,49 @@
 package editor
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // EnvEditor is the name of the environment variable specifying the editor for opening notes
 const EnvEditor = "EDITOR"
 
 const (
-	editorNameEmacs  = "emacs"
-	editorNameNano   = "nano"
-	editorNameNeovim = "nvim"
-	editorNameVi     = "vi"
-	editorNameVim    = "vim"
+	// placeholderFile is replaced by the path of the file to open in an editor command template
+	placeholderFile = "{file}"
+	// placeholderLine is replaced by the line at which to place the cursor in an editor command template
+	placeholderLine = "{line}"
+
+	editorNameVim = "vim"
 )
 
+// presets are the arguments, formatted as a command template without the program, for opening a file at a line in
+// editors identified by the name of their program
+var presets = map[string]string{
+	"vi":     "+{line} {file}",
+	"vim":    "+{line} {file}",
+	"nvim":   "+{line} {file}",
+	"emacs":  "+{line} {file}",
+	"nano":   "+{line} {file}",
+	"micro":  "+{line} {file}",
+	"kak":    "+{line} {file}",
+	"gedit":  "+{line} {file}",
+	"hx":     "{file}:{line}",
+	"helix":  "{file}:{line}",
+	"code":   "--wait --goto {file}:{line}",
+	"codium": "--wait --goto {file}:{line}",
+	"subl":   "-w {file}:{line}",
+	"zed":    "--wait {file}:{line}",
+	"mate":   "-w -l {line} {file}",
+	"kate":   "--block --line {line} {file}",
+}
+
 // openable is the interface that an editor opens
 type openable interface {
 	GetFilePath() string
@@ -26,8 +53,8 @@ type openable interface {
 // Editor encapsulates the commands and args necessary to open an editor in a shell
 type Editor struct {
 	Cmd       string
-	GetArgs   func(int) []string
-	Supported bool
+	Args      []string // arguments of the command, which may contain {file} and {line} placeholders
+	Supported bool     // whether the arguments place the cursor at a line
 	Default   bool
 }
 
@@ -35,82 +62,139 @@ type Editor struct {
 // NOTE: it is recommended to use Go >= v.1.15.7 due to call to exec.Command()
 // See: https://blog.golang.org/path-security
 func (e *Editor) Open(o openable) error {
-	args := append(e.GetArgs(o.GetFileCursorLine()), o.GetFilePath())
-	cmd := exec.Command(e.Cmd, args...)
+	cmd := exec.Command(e.Cmd, e.GetArgs(o.GetFilePath(), o.GetFileCursorLine())...)
 	cmd.Stdout = os.Stdout
 	cmd.Stdin = os.Stdin
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-// GetEditor gets an Editor based on a provided name
-func GetEditor(name string) *Editor {
-	switch name {
-	case editorNameVi, editorNameVim:
-		return &Editor{
-			Cmd: name,
-			GetArgs: func(line int) []string {
-				return []string{
-					fmt.Sprintf("+%d", line),
-				}
-			},
-			Supported: true,
-			Default:   false,
-		}
-	case editorNameEmacs:
-		return &Editor{
-			Cmd: editorNameEmacs,
-			GetArgs: func(line int) []string {
-				return []string{
-					fmt.Sprintf("+%d", line),
-				}
-			},
-			Supported: true,
-			Default:   false,
-		}
-	case editorNameNano:
-		return &Editor{
-			Cmd: editorNameNano,
-			GetArgs: func(line int) []string {
-				return []string{
-					fmt.Sprintf("+%d", line),
-				}
-			},
-			Supported: true,
-			Default:   false,
-		}
-	case editorNameNeovim:
-		return &Editor{
-			Cmd: editorNameNeovim,
-			GetArgs: func(line int) []string {
-				return []string{
-					fmt.Sprintf("+%d", line),
-				}
-			},
-			Supported: true,
-			Default:   false,
+// GetArgs returns the arguments of the command for opening a file at a line, with the file appended if the arguments
+// do not contain the {file} placeholder
+func (e *Editor) GetArgs(filePath string, line int) []string {
+	replacer := strings.NewReplacer(placeholderFile, filePath, placeholderLine, strconv.Itoa(line))
+	args := []string{}
+	hasFile := false
+	for _, arg := range e.Args {
+		if strings.Contains(arg, placeholderFile) {
+			hasFile = true
 		}
+		args = append(args, replacer.Replace(arg))
+	}
+	if !hasFile {
+		args = append(args, filePath)
+	}
+	return args
+}
+
+// GetEditor gets an Editor from a command template, such as "code --wait --goto {file}:{line}", or otherwise from the
+// value of the EDITOR environment variable. A command without placeholders is completed with the preset arguments of
+// its program, if any, and Vim is used if no command is provided.
+func GetEditor(command string, env string) (*Editor, error) {
+	if strings.TrimSpace(command) == "" {
+		command = env
+	}
+	words, err := SplitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
 	// use Vim as the default editor
-	case "":
+	if len(words) == 0 {
+		args, _ := SplitCommand(presets[editorNameVim])
 		return &Editor{
-			Cmd: editorNameVim,
-			GetArgs: func(line int) []string {
-				return []string{
-					fmt.Sprintf("+%d", line),
-				}
-			},
+			Cmd:       editorNameVim,
+			Args:      args,
 			Supported: true,
 			Default:   true,
+		}, nil
+	}
+
+	ed := &Editor{
+		Cmd:  words[0],
+		Args: words[1:],
+	}
+	if strings.Contains(command, placeholderFile) || strings.Contains(command, placeholderLine) {
+		ed.Supported = strings.Contains(command, placeholderLine)
+		return ed, nil
+	}
+	// unrecognized editor will be passed no arguments other than its own and the file
+	if preset, found := presets[filepath.Base(ed.Cmd)]; found {
+		args, _ := SplitCommand(preset)
+		ed.Args = mergeArgs(ed.Args, args)
+		ed.Supported = strings.Contains(preset, placeholderLine)
+	}
+	return ed, nil
+}
+
+// mergeArgs appends the arguments of a preset to the arguments of a command, skipping arguments without placeholders
+// that the command already passes, such as the --wait flag of "code --wait"
+func mergeArgs(args []string, preset []string) []string {
+	merged := append([]string{}, args...)
+	for _, arg := range preset {
+		hasPlaceholder := strings.Contains(arg, placeholderFile) || strings.Contains(arg, placeholderLine)
+		if !hasPlaceholder && slices.Contains(args, arg) {
+			continue
 		}
-	// unrecognized editor will be passed no arguments
-	default:
-		return &Editor{
-			Cmd: name,
-			GetArgs: func(line int) []string {
-				return []string{}
-			},
-			Supported: false,
-			Default:   false,
+		merged = append(merged, arg)
+	}
+	return merged
+}
+
+// SplitCommand splits a command into words separated by whitespace as a shell would, with single quotes, double
+// quotes, and backslashes outside of single quotes preserving whitespace within a word
+func SplitCommand(command string) ([]string, error) {
+	words := []string{}
+	var (
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			word.WriteRune(r)
+		case r == '\\':
+			inWord = true
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
 		}
 	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in editor command [%s]", quote, command)
+	}
+	if escaped {
+		return nil, errors.New("editor command must not end with a backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
 }
@@ -0,0 +1,195 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEditor(t *testing.T) {
+	type testCase struct {
+		command      string
+		env          string
+		expectedArgs []string
+		expected     *Editor
+	}
+
+	tests := map[string]testCase{
+		"default editor": {
+			command:      "",
+			env:          "",
+			expectedArgs: []string{"+4", "note.txt"},
+			expected: &Editor{
+				Cmd:       "vim",
+				Args:      []string{"+{line}", "{file}"},
+				Supported: true,
+				Default:   true,
+			},
+		},
+		"preset editor from environment": {
+			command:      "",
+			env:          "nano",
+			expectedArgs: []string{"+4", "note.txt"},
+			expected: &Editor{
+				Cmd:       "nano",
+				Args:      []string{"+{line}", "{file}"},
+				Supported: true,
+			},
+		},
+		"preset editor from environment with path and arguments": {
+			command:      "",
+			env:          "/usr/local/bin/code -n",
+			expectedArgs: []string{"-n", "--wait", "--goto", "note.txt:4"},
+			expected: &Editor{
+				Cmd:       "/usr/local/bin/code",
+				Args:      []string{"-n", "--wait", "--goto", "{file}:{line}"},
+				Supported: true,
+			},
+		},
+		"preset editor from environment with preset arguments": {
+			command:      "",
+			env:          "code --wait",
+			expectedArgs: []string{"--wait", "--goto", "note.txt:4"},
+			expected: &Editor{
+				Cmd:       "code",
+				Args:      []string{"--wait", "--goto", "{file}:{line}"},
+				Supported: true,
+			},
+		},
+		"unrecognized editor from environment": {
+			command:      "",
+			env:          "ed -s",
+			expectedArgs: []string{"-s", "note.txt"},
+			expected: &Editor{
+				Cmd:       "ed",
+				Args:      []string{"-s"},
+				Supported: false,
+			},
+		},
+		"command template takes precedence over environment": {
+			command:      "hx {file}:{line}",
+			env:          "vim",
+			expectedArgs: []string{"note.txt:4"},
+			expected: &Editor{
+				Cmd:       "hx",
+				Args:      []string{"{file}:{line}"},
+				Supported: true,
+			},
+		},
+		"command template without line": {
+			command:      "gedit --new-window {file}",
+			env:          "",
+			expectedArgs: []string{"--new-window", "note.txt"},
+			expected: &Editor{
+				Cmd:       "gedit",
+				Args:      []string{"--new-window", "{file}"},
+				Supported: false,
+			},
+		},
+		"command template without file": {
+			command:      "myeditor --line={line}",
+			env:          "",
+			expectedArgs: []string{"--line=4", "note.txt"},
+			expected: &Editor{
+				Cmd:       "myeditor",
+				Args:      []string{"--line={line}"},
+				Supported: true,
+			},
+		},
+		"command without placeholders uses preset": {
+			command:      "subl",
+			env:          "vim",
+			expectedArgs: []string{"-w", "note.txt:4"},
+			expected: &Editor{
+				Cmd:       "subl",
+				Args:      []string{"-w", "{file}:{line}"},
+				Supported: true,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ed, err := GetEditor(test.command, test.env)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ed)
+			require.Equal(t, test.expectedArgs, ed.GetArgs("note.txt", 4))
+		})
+	}
+}
+
+func TestGetEditorFail(t *testing.T) {
+	_, err := GetEditor("code --goto '{file}:{line}", "")
+	require.Error(t, err)
+}
+
+func TestSplitCommand(t *testing.T) {
+	type testCase struct {
+		command     string
+		expected    []string
+		shouldError bool
+	}
+
+	tests := map[string]testCase{
+		"empty": {
+			command:  "",
+			expected: []string{},
+		},
+		"whitespace": {
+			command:  " \t ",
+			expected: []string{},
+		},
+		"single word": {
+			command:  "vim",
+			expected: []string{"vim"},
+		},
+		"multiple words with repeated whitespace": {
+			command:  "  code   --wait\t--goto ",
+			expected: []string{"code", "--wait", "--goto"},
+		},
+		"single quotes": {
+			command:  `'/Applications/My Editor' '{file}'`,
+			expected: []string{"/Applications/My Editor", "{file}"},
+		},
+		"double quotes": {
+			command:  `"/Applications/My Editor" --title="a \"b\" c"`,
+			expected: []string{"/Applications/My Editor", `--title=a "b" c`},
+		},
+		"backslash in single quotes": {
+			command:  `ed 'a\b'`,
+			expected: []string{"ed", `a\b`},
+		},
+		"escaped whitespace": {
+			command:  `/opt/My\ Editor/bin/ed -n`,
+			expected: []string{"/opt/My Editor/bin/ed", "-n"},
+		},
+		"empty quoted word": {
+			command:  `ed ''`,
+			expected: []string{"ed", ""},
+		},
+		"unterminated single quote": {
+			command:     `ed 'a`,
+			shouldError: true,
+		},
+		"unterminated double quote": {
+			command:     `ed "a`,
+			shouldError: true,
+		},
+		"trailing backslash": {
+			command:     `ed \`,
+			shouldError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			words, err := SplitCommand(test.command)
+			if test.shouldError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, words)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionGzip is the name of gzip compression
+	CompressionGzip = "gzip"
+	// CompressionZstd is the name of zstd compression
+	CompressionZstd = "zstd"
+)
+
+// compressionExts maps the name of a compression format to the extension appended to compressed file names
+var compressionExts = map[string]string{
+	CompressionGzip: "gz",
+	CompressionZstd: "zst",
+}
+
+// Compressions lists the names of all compression formats, including the empty name for no compression
+var Compressions = []string{"", CompressionGzip, CompressionZstd}
+
+// AddCompressionExt appends the extension of a compression format to a file name
+func AddCompressionExt(fileName string, compression string) string {
+	ext, found := compressionExts[compression]
+	if !found {
+		return fileName
+	}
+	return fileName + "." + ext
+}
+
+// SplitCompressionExt splits a file name into the name without the extension of a compression format and
+// the name of the compression format, which is empty for an uncompressed file name
+func SplitCompressionExt(fileName string) (string, string) {
+	for compression, ext := range compressionExts {
+		if strings.HasSuffix(fileName, "."+ext) {
+			return strings.TrimSuffix(fileName, "."+ext), compression
+		}
+	}
+	return fileName, ""
+}
+
+// newDecompressingReader wraps a reader to decompress the contents of a file based on its name
+func newDecompressingReader(fileName string, r io.Reader) (io.ReadCloser, error) {
+	_, compression := SplitCompressionExt(fileName)
+	switch compression {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// newCompressingWriter wraps a writer to compress the contents of a file based on its name
+func newCompressingWriter(fileName string, w io.Writer) (io.WriteCloser, error) {
+	_, compression := SplitCompressionExt(fileName)
+	switch compression {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -2,7 +2,10 @@ package file
 
 import (
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // ReadWriteable is the interface on which file operations are executed
@@ -20,9 +23,16 @@ func NewReadWriter() *ReadWriter {
 	return &ReadWriter{}
 }
 
-// Read reads from file
+// Read reads from file, decompressing the contents of a file with the extension of a compression format
 func (rw *ReadWriter) Read(rwable ReadWriteable) error {
-	r, err := os.Open(rwable.GetFilePath())
+	fileName := rwable.GetFilePath()
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := newDecompressingReader(fileName, f)
 	if err != nil {
 		return err
 	}
@@ -30,19 +40,74 @@ func (rw *ReadWriter) Read(rwable ReadWriteable) error {
 	return rwable.Load(r)
 }
 
-// Overwrite writes a template to a file, overwriting existing file contents if any
+// Overwrite writes a template to a file, overwriting existing file contents if any and creating
+// the file's parent directories if they do not exist and compressing the contents of a file with the extension
+// of a compression format. Contents are written to a temporary file that is synced to disk and renamed to
+// replace the file so that an interrupted write never leaves a partially written file. An existing file keeps its
+// permissions and a new file is created with permissions 0o644.
 func (rw *ReadWriter) Overwrite(rwable ReadWriteable) error {
-	f, err := os.OpenFile(rwable.GetFilePath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	fileName := rwable.GetFilePath()
+	dir := filepath.Dir(fileName)
+	err := os.MkdirAll(dir, 0o755)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	err = rwable.Write(f)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(fileName)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+	defer os.Remove(tmpName) // no-op after a successful rename
+
+	err = writeCompressed(f, fileName, rwable)
 	if err != nil {
+		f.Close()
 		return err
 	}
-	return nil
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tmpName, fileMode(fileName))
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpName, fileName)
+}
+
+// fileMode returns the permissions of an existing file so that overwriting it preserves them, defaulting to 0o644
+// for a file that does not exist
+func fileMode(fileName string) fs.FileMode {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return 0o644
+	}
+	return info.Mode().Perm()
+}
+
+// writeCompressed writes a template to a writer, compressing the contents based on a file name
+func writeCompressed(w io.Writer, fileName string, rwable ReadWriteable) error {
+	cw, err := newCompressingWriter(fileName, w)
+	if err != nil {
+		return err
+	}
+	err = rwable.Write(cw)
+	if err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+// Remove removes a file
+func (rw *ReadWriter) Remove(rwable ReadWriteable) error {
+	return os.Remove(rwable.GetFilePath())
 }
 
 // Exists evaluates if a file exists
@@ -51,3 +116,52 @@ func (rw *ReadWriter) Exists(rwable ReadWriteable) bool {
 	_, err := os.Stat(fileName)
 	return !os.IsNotExist(err)
 }
+
+// ListFiles returns the paths, relative to a directory, of all files in the directory and its subdirectories
+// up to a maximum depth (unlimited if negative), skipping hidden subdirectories and the specified subdirectories
+func ListFiles(dir string, maxDepth int, skipDirs ...string) ([]string, error) {
+	fileNames := []string{}
+
+	skip := map[string]struct{}{}
+	for _, skipDir := range skipDirs {
+		skip[filepath.Clean(skipDir)] = struct{}{}
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		if d.IsDir() {
+			if _, found := skip[rel]; found || strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if maxDepth >= 0 && strings.Count(rel, string(filepath.Separator)) >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		fileNames = append(fileNames, rel)
+		return nil
+	})
+	return fileNames, err
+}
+
+// RemoveEmptyParents removes the empty parent directories of a file up to, but not including, a root directory
+func RemoveEmptyParents(path string, root string) {
+	root = filepath.Clean(root)
+	for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		// removing a non-empty directory fails, leaving it and its parents in place
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
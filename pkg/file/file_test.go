@@ -0,0 +1,77 @@
+package file
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testReadWriteable struct {
+	path     string
+	contents string
+}
+
+func (t *testReadWriteable) Load(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	t.contents = string(b)
+	return err
+}
+
+func (t *testReadWriteable) Write(w io.Writer) error {
+	_, err := io.WriteString(w, t.contents)
+	return err
+}
+
+func (t *testReadWriteable) GetFilePath() string {
+	return t.path
+}
+
+func TestOverwriteMode(t *testing.T) {
+	type testCase struct {
+		existingMode os.FileMode
+		expectedMode os.FileMode
+	}
+
+	tests := map[string]testCase{
+		"new file": {
+			expectedMode: 0o644,
+		},
+		"existing file with default mode": {
+			existingMode: 0o644,
+			expectedMode: 0o644,
+		},
+		"existing private file": {
+			existingMode: 0o600,
+			expectedMode: 0o600,
+		},
+		"existing executable file": {
+			existingMode: 0o755,
+			expectedMode: 0o755,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notes", "2021-01-05.txt")
+			if test.existingMode != 0 {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte("old"), test.existingMode))
+				require.NoError(t, os.Chmod(path, test.existingMode)) // not subject to umask
+			}
+
+			rw := NewReadWriter()
+			require.NoError(t, rw.Overwrite(&testReadWriteable{path: path, contents: "new"}))
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedMode, info.Mode().Perm())
+
+			loaded := &testReadWriteable{path: path}
+			require.NoError(t, rw.Read(loaded))
+			require.Equal(t, "new", loaded.contents)
+		})
+	}
+}
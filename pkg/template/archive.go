@@ -1,51 +1,98 @@
 package template
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/dkaslovsky/textnote/pkg/config"
+	"github.com/dkaslovsky/textnote/pkg/file"
 )
 
-// MonthArchiveTemplate contains the structure of a month archive
-type MonthArchiveTemplate struct {
+// ArchiveTemplate contains the structure of an archive of the notes of a period
+type ArchiveTemplate struct {
 	*Template
+	compression string // compression format of the archive file
+	section     string // name of the only section of an archive separated by section, empty for all sections
 }
 
-// NewMonthArchiveTemplate constructs a new MonthArchiveTemplate
-func NewMonthArchiveTemplate(opts config.Opts, date time.Time) *MonthArchiveTemplate {
-	monthDate := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
-	return &MonthArchiveTemplate{
-		NewTemplate(opts, monthDate),
+// NewArchiveTemplate constructs a new ArchiveTemplate for the period containing the specified date
+func NewArchiveTemplate(opts config.Opts, period Period, date time.Time) *ArchiveTemplate {
+	t := NewTemplate(opts, period.Start(date))
+	t.period = period
+	return &ArchiveTemplate{
+		Template:    t,
+		compression: opts.Archive.Compress,
 	}
 }
 
-// Write writes the template
-// This function is needed to ensure the string() method of the MonthArchiveTemplate is called
-func (t *MonthArchiveTemplate) Write(w io.Writer) error {
-	_, err := w.Write([]byte(t.string()))
-	return err
+// NewSectionArchiveTemplate constructs a new ArchiveTemplate containing only the specified section for the period
+// containing the specified date, which is written to an archive file separate from that of the other sections
+func NewSectionArchiveTemplate(opts config.Opts, period Period, date time.Time, section string) *ArchiveTemplate {
+	opts.Section.Names = []string{section}
+	t := NewArchiveTemplate(opts, period, date)
+	t.section = section
+	return t
+}
+
+// NewMonthArchiveTemplate constructs a new ArchiveTemplate for the month containing the specified date
+func NewMonthArchiveTemplate(opts config.Opts, date time.Time) *ArchiveTemplate {
+	return NewArchiveTemplate(opts, PeriodMonth, date)
+}
+
+// Write writes the template incrementally through a buffered writer
+// This function is needed to ensure the write() method of the ArchiveTemplate is called
+func (t *ArchiveTemplate) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	t.write(bw)
+	return bw.Flush()
+}
+
+// GetLabel returns the label identifying the archive's period in its file name and header
+func (t *ArchiveTemplate) GetLabel() string {
+	return formatArchiveLabel(t.period, t.date, t.opts.Archive)
+}
+
+// GetSectionName returns the name of the only section of an archive separated by section, empty for an archive
+// of all sections
+func (t *ArchiveTemplate) GetSectionName() string {
+	return t.section
+}
+
+// GetCompression returns the compression format of the archive file
+func (t *ArchiveTemplate) GetCompression() string {
+	return t.compression
+}
+
+// SetCompression overrides the configured compression format of the archive file
+func (t *ArchiveTemplate) SetCompression(compression string) {
+	t.compression = compression
 }
 
 // GetFilePath generates a full path for a file based on the template date
-func (t *MonthArchiveTemplate) GetFilePath() string {
+func (t *ArchiveTemplate) GetFilePath() string {
+	prefix := t.opts.Archive.FilePrefix
+	if t.section != "" {
+		prefix += formatSectionLabel(t.section) + "-"
+	}
 	name := filepath.Join(
 		t.opts.AppDir,
-		t.opts.Archive.FilePrefix+t.date.Format(t.opts.Archive.MonthTimeFormat),
+		prefix+t.GetLabel(),
 	)
-	if t.opts.File.Ext == "" {
-		return name
+	if t.opts.File.Ext != "" {
+		name = fmt.Sprintf("%s.%s", name, t.opts.File.Ext)
 	}
-	return fmt.Sprintf("%s.%s", name, t.opts.File.Ext)
+	return file.AddCompressionExt(name, t.compression)
 }
 
 // ArchiveSectionContents concatenates the contents of the specified section from a source template and
 // appends to the contents of the receiver's section with a header derived from the source template's date
-func (t *MonthArchiveTemplate) ArchiveSectionContents(src *Template, sectionName string) error {
+func (t *ArchiveTemplate) ArchiveSectionContents(src *Template, sectionName string) error {
 	tgtSec, err := t.getSection(sectionName)
 	if err != nil {
 		return fmt.Errorf("failed to find section in target: %w", err)
@@ -56,10 +103,7 @@ func (t *MonthArchiveTemplate) ArchiveSectionContents(src *Template, sectionName
 	}
 
 	// flatten text from contents into a single string
-	txt := ""
-	for _, content := range srcSec.contents {
-		txt += content.text
-	}
+	txt := srcSec.getText()
 	if len(txt) == 0 {
 		return nil
 	}
@@ -71,42 +115,258 @@ func (t *MonthArchiveTemplate) ArchiveSectionContents(src *Template, sectionName
 	return nil
 }
 
-// Merge merges a source MonthArchiveTemplate into the receiver
-// This is a convenience function that iterates and copies all sections in the receiver
-func (t *MonthArchiveTemplate) Merge(src *MonthArchiveTemplate) error {
-	for sectionName := range t.sectionIdx {
-		err := t.CopySectionContents(src, sectionName)
+// ContainsContents evaluates if the contents of the specified sections of a source template are archived in the
+// receiver under a header derived from the source template's date, ignoring blank lines
+func (t *ArchiveTemplate) ContainsContents(src *Template, sectionNames []string) bool {
+	header := t.makeContentHeader(src.GetDate())
+
+	for _, sectionName := range sectionNames {
+		srcSec, err := src.getSection(sectionName)
+		if err != nil {
+			return false
+		}
+		srcTxt := removeBlankLines(srcSec.getText())
+		if srcTxt == "" {
+			continue
+		}
+
+		tgtSec, err := t.getSection(srcSec.name)
+		if err != nil {
+			return false
+		}
+		// archived text is delimited by newlines so that only whole lines are matched
+		archivedTxt := strings.Builder{}
+		archivedTxt.WriteString("\n")
+		for _, content := range tgtSec.contents {
+			if content.header == header {
+				archivedTxt.WriteString(removeBlankLines(content.text))
+				archivedTxt.WriteString("\n")
+			}
+		}
+		if !strings.Contains(archivedTxt.String(), "\n"+srcTxt+"\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// RemoveDuplicates removes all but the last of the contents archived for the same date in each section
+func (t *ArchiveTemplate) RemoveDuplicates() {
+	for _, section := range t.sections {
+		last := map[string]int{}
+		for i, content := range section.contents {
+			if content.header != "" {
+				last[content.header] = i
+			}
+		}
+		contents := []contentItem{}
+		for i, content := range section.contents {
+			if idx, found := last[content.header]; found && idx != i {
+				continue
+			}
+			contents = append(contents, content)
+		}
+		section.contents = contents
+	}
+}
+
+// Merge merges a source ArchiveTemplate into the receiver
+// Contents archived for a date in any section of the receiver replace all contents archived for the same date in
+// the source so that merging the same notes more than once does not duplicate them
+func (t *ArchiveTemplate) Merge(src *ArchiveTemplate) error {
+	headers := map[string]struct{}{}
+	for _, section := range t.sections {
+		for _, content := range section.contents {
+			if content.header != "" {
+				headers[content.header] = struct{}{}
+			}
+		}
+	}
+
+	// sections are merged by name rather than by the section index, which also maps aliases to sections
+	for _, tgtSec := range t.sections {
+		srcSec, err := src.getSection(tgtSec.name)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to find section in source: %w", err)
+		}
+		for _, content := range srcSec.contents {
+			if _, found := headers[content.header]; found {
+				continue
+			}
+			tgtSec.contents = append(tgtSec.contents, content)
 		}
 	}
 	return nil
 }
 
-func (t *MonthArchiveTemplate) string() string {
-	str := t.makeHeader()
+// GetDates returns the sorted unique dates of the notes contained in the archive
+func (t *ArchiveTemplate) GetDates() []time.Time {
+	found := map[time.Time]struct{}{}
 	for _, section := range t.sections {
-		name := section.getNameString(t.opts.Section.Prefix, t.opts.Section.Suffix)
+		for _, content := range section.contents {
+			date, ok := t.parseContentHeader(content.header)
+			if !ok {
+				continue
+			}
+			found[date] = struct{}{}
+		}
+	}
+
+	dates := []time.Time{}
+	for date := range found {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return dates
+}
+
+// ExtractDate constructs a new Template for a date populated with the contents archived for that date
+func (t *ArchiveTemplate) ExtractDate(date time.Time) *Template {
+	tgt := NewTemplate(t.opts, date)
+	t.ExtractDateInto(tgt)
+	return tgt
+}
+
+// ExtractDateInto appends the contents archived for the date of a target Template to the target's sections,
+// ignoring sections of the archive that are not in the target
+func (t *ArchiveTemplate) ExtractDateInto(tgt *Template) {
+	header := t.makeContentHeader(tgt.GetDate())
+
+	for _, section := range t.sections {
+		tgtSec, err := tgt.getSection(section.name)
+		if err != nil {
+			continue
+		}
+		for _, content := range section.contents {
+			if content.header != header || content.isEmpty() {
+				continue
+			}
+			tgtSec.appendText(content.text, tgt.opts.Section.GetTrailingNewlines(tgtSec.name))
+		}
+	}
+}
+
+// RemoveDate removes the contents archived for a date
+func (t *ArchiveTemplate) RemoveDate(date time.Time) {
+	header := t.makeContentHeader(date)
+
+	for _, section := range t.sections {
+		contents := []contentItem{}
+		for _, content := range section.contents {
+			if content.header == header {
+				continue
+			}
+			contents = append(contents, content)
+		}
+		section.contents = contents
+	}
+}
+
+func (t *ArchiveTemplate) string() string {
+	sb := &strings.Builder{}
+	bw := bufio.NewWriter(sb)
+	t.write(bw)
+	_ = bw.Flush() // writing to a strings.Builder does not fail
+	return sb.String()
+}
+
+// write writes the archive to a buffered writer, which retains the first error encountered to be returned on flush
+func (t *ArchiveTemplate) write(w *bufio.Writer) {
+	_, _ = w.WriteString(t.makeHeader())
+	if t.opts.Archive.TableOfContents {
+		t.writeTableOfContents(w)
+	}
+	for _, section := range t.sections {
+		_, _ = w.WriteString(section.getNameString(t.opts.Section.Prefix, t.opts.Section.Suffix))
 
 		section.sortContents()
-		body := section.getContentString()
-		body = regexp.MustCompile(`\n{2,}`).ReplaceAllString(body, "\n") // remove blank lines
+		bw := &blankLineRemover{w: w}
+		for _, content := range section.contents {
+			if content.header != "" {
+				bw.writeString(content.header)
+				bw.writeString("\n")
+			}
+			bw.writeString(content.text)
+			if !strings.HasSuffix(content.text, "\n") {
+				bw.writeString("\n")
+			}
+		}
+
+		_, _ = w.WriteString(strings.Repeat("\n", t.opts.Section.GetTrailingNewlines(section.name)))
+	}
+}
+
+// blankLineRemover writes text with blank lines removed by collapsing consecutive newlines into a single newline,
+// including newlines spanning separate writes
+type blankLineRemover struct {
+	w       *bufio.Writer
+	newline bool // the last byte written is a newline
+}
 
-		str += fmt.Sprintf("%s%s%s", name, body, strings.Repeat("\n", t.opts.Section.TrailingNewlines))
+func (r *blankLineRemover) writeString(s string) {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\n' {
+			r.newline = false
+			continue
+		}
+		if r.newline {
+			_, _ = r.w.WriteString(s[start:i])
+			start = i + 1
+			continue
+		}
+		r.newline = true
 	}
-	return str
+	_, _ = r.w.WriteString(s[start:])
 }
 
-func (t *MonthArchiveTemplate) makeHeader() string {
+// writeTableOfContents writes a table of contents listing each archived date with the sections containing contents
+// archived for that date, summarized by the number of dates archived in each section. The table of contents is
+// generated from the archived contents each time the archive is written and is ignored when an archive is loaded.
+func (t *ArchiveTemplate) writeTableOfContents(w *bufio.Writer) {
+	headers := []string{}
+	headerSections := map[string][]string{}
+	sectionCounts := []string{}
+
+	for _, section := range t.sections {
+		count := 0
+		for _, content := range section.contents {
+			if content.header == "" || content.isEmpty() {
+				continue
+			}
+			sections, found := headerSections[content.header]
+			if !found {
+				headers = append(headers, content.header)
+			}
+			if len(sections) > 0 && sections[len(sections)-1] == section.name {
+				continue
+			}
+			headerSections[content.header] = append(sections, section.name)
+			count++
+		}
+		sectionCounts = append(sectionCounts, fmt.Sprintf("%s: %d", section.name, count))
+	}
+	sort.Strings(headers)
+
+	fmt.Fprintf(w, "%d dates archived (%s)\n", len(headers), strings.Join(sectionCounts, ", "))
+	for _, header := range headers {
+		fmt.Fprintf(w, "- %s %s\n", header, strings.Join(headerSections[header], ", "))
+	}
+	_, _ = w.WriteString("\n")
+}
+
+func (t *ArchiveTemplate) makeHeader() string {
 	return fmt.Sprintf("%s%s%s\n%s",
 		t.opts.Archive.HeaderPrefix,
-		t.date.Format(t.opts.Archive.MonthTimeFormat),
+		t.GetLabel(),
 		t.opts.Archive.HeaderSuffix,
 		strings.Repeat("\n", t.opts.Header.TrailingNewlines),
 	)
 }
 
-func (t *MonthArchiveTemplate) makeContentHeader(date time.Time) string {
+func (t *ArchiveTemplate) makeContentHeader(date time.Time) string {
 	return fmt.Sprintf("%s%s%s",
 		t.opts.Archive.SectionContentPrefix,
 		date.Format(t.opts.Archive.SectionContentTimeFormat),
@@ -114,6 +374,140 @@ func (t *MonthArchiveTemplate) makeContentHeader(date time.Time) string {
 	)
 }
 
+// parseContentHeader extracts the date from the header of archived contents, returning an additional bool
+// indicating if the header is valid
+func (t *ArchiveTemplate) parseContentHeader(header string) (time.Time, bool) {
+	if !isArchiveItemHeader(
+		header,
+		t.opts.Archive.SectionContentPrefix,
+		t.opts.Archive.SectionContentSuffix,
+		t.opts.Archive.SectionContentTimeFormat,
+	) {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(
+		t.opts.Archive.SectionContentTimeFormat,
+		stripPrefixSuffix(header, t.opts.Archive.SectionContentPrefix, t.opts.Archive.SectionContentSuffix),
+	)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+var blankLinesRegex = regexp.MustCompile(`\n{2,}`)
+
+// removeBlankLines removes blank lines and leading and trailing newlines from text
+func removeBlankLines(text string) string {
+	return strings.Trim(blankLinesRegex.ReplaceAllString(text, "\n"), "\n")
+}
+
+// ParseArchiveFileName extracts the Period and starting time.Time of an archive from a file name and returns an
+// additional bool indicating if the name corresponds to a valid archive file name for any archive period, with
+// or without the extension of a compression format
+func ParseArchiveFileName(fileName string, opts config.Opts) (p Period, t time.Time, ok bool) {
+	fileName, _ = file.SplitCompressionExt(fileName)
+	ext := filepath.Ext(fileName)
+	if ext == "." {
+		return p, t, false
+	}
+	if strings.TrimPrefix(ext, ".") != opts.File.Ext {
+		return p, t, false
+	}
+	baseName := strings.TrimSuffix(fileName, ext)
+	if !strings.HasPrefix(baseName, opts.Archive.FilePrefix) {
+		return p, t, false
+	}
+	label := strings.TrimPrefix(baseName, opts.Archive.FilePrefix)
+
+	for _, p := range archivePeriods {
+		t, ok := parseArchiveLabel(p, label, opts.Archive)
+		if ok {
+			return p, t, true
+		}
+	}
+	return p, t, false
+}
+
+// ParseSectionArchiveFileName extracts the section name, Period, and starting time.Time of an archive separated by
+// section from a file name and returns an additional bool indicating if the name corresponds to a valid file name
+// of an archive separated by any configured section
+func ParseSectionArchiveFileName(fileName string, opts config.Opts) (section string, p Period, t time.Time, ok bool) {
+	for _, section := range opts.Section.Names {
+		sectionOpts := opts
+		sectionOpts.Archive.FilePrefix = opts.Archive.FilePrefix + formatSectionLabel(section) + "-"
+		p, t, ok := ParseArchiveFileName(fileName, sectionOpts)
+		if ok {
+			return section, p, t, true
+		}
+	}
+	return section, p, t, false
+}
+
+// formatSectionLabel formats the label identifying a section in the file name of an archive separated by section
+func formatSectionLabel(section string) string {
+	return strings.Trim(nonAlphanumericRegex.ReplaceAllString(strings.ToLower(section), "-"), "-")
+}
+
+var nonAlphanumericRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// formatArchiveLabel formats the label identifying the archive for the period containing a date
+func formatArchiveLabel(p Period, date time.Time, opts config.ArchiveOpts) string {
+	switch p {
+	case PeriodWeek:
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", date.Year(), (int(date.Month())-1)/3+1)
+	case PeriodYear:
+		return date.Format("2006")
+	default:
+		return date.Format(opts.MonthTimeFormat)
+	}
+}
+
+// parseArchiveLabel parses the starting date of the archive of the specified period from a label, returning an
+// additional bool indicating if the label is valid for the period
+func parseArchiveLabel(p Period, label string, opts config.ArchiveOpts) (time.Time, bool) {
+	var t time.Time
+
+	switch p {
+	case PeriodWeek:
+		var year, week int
+		_, err := fmt.Sscanf(label, "%d-W%d", &year, &week)
+		if err != nil {
+			return t, false
+		}
+		// January 4th is always in the first ISO week of its year
+		t = PeriodWeek.Start(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, 7*(week-1))
+	case PeriodQuarter:
+		var year, quarter int
+		_, err := fmt.Sscanf(label, "%d-Q%d", &year, &quarter)
+		if err != nil {
+			return t, false
+		}
+		t = time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	case PeriodYear:
+		parsed, err := time.Parse("2006", label)
+		if err != nil {
+			return t, false
+		}
+		t = parsed
+	default:
+		parsed, err := time.Parse(opts.MonthTimeFormat, label)
+		if err != nil {
+			return t, false
+		}
+		t = p.Start(parsed)
+	}
+
+	// require the label to be exactly that generated for the period so that no period is mistaken for another
+	if formatArchiveLabel(p, t, opts) != label {
+		return t, false
+	}
+	return t, true
+}
+
 // isArchiveItemHeader evaluates if a line matches the pattern of a dated header in a section of an archive
 func isArchiveItemHeader(line string, prefix string, suffix string, format string) bool {
 	if !strings.HasPrefix(line, prefix) {
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dkaslovsky/textnote/pkg/config"
 	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +40,56 @@ func TestNewMonthArchiveTemplate(t *testing.T) {
 	}
 }
 
+func TestNewArchiveTemplate(t *testing.T) {
+	type testCase struct {
+		period           Period
+		date             time.Time
+		expectedDate     time.Time
+		expectedFilePath string
+		expectedHeader   string
+	}
+
+	tests := map[string]testCase{
+		"week": {
+			period:           PeriodWeek,
+			date:             time.Date(2021, 1, 2, 2, 3, 4, 5, time.UTC),
+			expectedDate:     time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC),
+			expectedFilePath: "path/to/app/dir/archive-2020-W53.txt",
+			expectedHeader:   "ARCHIVEPREFIX 2020-W53 ARCHIVESUFFIX\n\n",
+		},
+		"month": {
+			period:           PeriodMonth,
+			date:             time.Date(2021, 2, 15, 2, 3, 4, 5, time.UTC),
+			expectedDate:     time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+			expectedFilePath: "path/to/app/dir/archive-Feb2021.txt",
+			expectedHeader:   "ARCHIVEPREFIX Feb2021 ARCHIVESUFFIX\n\n",
+		},
+		"quarter": {
+			period:           PeriodQuarter,
+			date:             time.Date(2021, 6, 30, 2, 3, 4, 5, time.UTC),
+			expectedDate:     time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC),
+			expectedFilePath: "path/to/app/dir/archive-2021-Q2.txt",
+			expectedHeader:   "ARCHIVEPREFIX 2021-Q2 ARCHIVESUFFIX\n\n",
+		},
+		"year": {
+			period:           PeriodYear,
+			date:             time.Date(2021, 6, 30, 2, 3, 4, 5, time.UTC),
+			expectedDate:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedFilePath: "path/to/app/dir/archive-2021.txt",
+			expectedHeader:   "ARCHIVEPREFIX 2021 ARCHIVESUFFIX\n\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := NewArchiveTemplate(templatetest.GetOpts(), test.period, test.date)
+			require.Equal(t, test.expectedDate, a.GetDate())
+			require.Equal(t, test.expectedFilePath, a.GetFilePath())
+			require.Equal(t, test.expectedHeader, a.makeHeader())
+		})
+	}
+}
+
 func TestArchiveGetFilePath(t *testing.T) {
 	t.Run("get file path with extension", func(t *testing.T) {
 		opts := templatetest.GetOpts()
@@ -65,6 +116,28 @@ func TestArchiveGetFilePath(t *testing.T) {
 			stripPrefixSuffix(filePath, fmt.Sprintf("%s/", opts.AppDir), ""),
 		)
 	})
+
+	t.Run("get file path with compression", func(t *testing.T) {
+		opts := templatetest.GetOpts()
+		opts.Archive.Compress = "zstd"
+		template := NewMonthArchiveTemplate(opts, templatetest.Date)
+		require.Equal(t, "path/to/app/dir/archive-Dec2020.txt.zst", template.GetFilePath())
+
+		template.SetCompression("gzip")
+		require.Equal(t, "path/to/app/dir/archive-Dec2020.txt.gz", template.GetFilePath())
+
+		template.SetCompression("")
+		require.Equal(t, "path/to/app/dir/archive-Dec2020.txt", template.GetFilePath())
+	})
+
+	t.Run("get file path of archive separated by section", func(t *testing.T) {
+		opts := templatetest.GetOpts()
+		template := NewSectionArchiveTemplate(opts, PeriodMonth, templatetest.Date, "TestSection1")
+		require.Equal(t, "path/to/app/dir/archive-testsection1-Dec2020.txt", template.GetFilePath())
+
+		template = NewSectionArchiveTemplate(opts, PeriodMonth, templatetest.Date, " To Do / Notes ")
+		require.Equal(t, "path/to/app/dir/archive-to-do-notes-Dec2020.txt", template.GetFilePath())
+	})
 }
 
 func TestArchiveSectionContents(t *testing.T) {
@@ -552,6 +625,407 @@ text 2020-12-18
 	}
 }
 
+func TestArchiveTableOfContents(t *testing.T) {
+	opts := templatetest.GetOpts()
+	opts.Archive.TableOfContents = true
+
+	existing := NewMonthArchiveTemplate(opts, templatetest.Date)
+	existing.sections[0].contents = []contentItem{
+		{header: "[2020-12-17]", text: "text1a"},
+		{header: "[2020-12-18]", text: "text1b"},
+	}
+	existing.sections[2].contents = []contentItem{
+		{header: "[2020-12-17]", text: "text3"},
+	}
+	existingText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+2 dates archived (TestSection1: 2, TestSection2: 0, TestSection3: 1)
+- [2020-12-17] TestSection1, TestSection3
+- [2020-12-18] TestSection1
+
+_p_TestSection1_q_
+[2020-12-17]
+text1a
+[2020-12-18]
+text1b
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+[2020-12-17]
+text3
+
+
+
+`
+	require.Equal(t, existingText, existing.string())
+
+	t.Run("table of contents is ignored when loading", func(t *testing.T) {
+		loaded := NewMonthArchiveTemplate(opts, templatetest.Date)
+		err := loaded.Load(strings.NewReader(existingText))
+		require.NoError(t, err)
+		require.Equal(t, existingText, loaded.string())
+	})
+
+	t.Run("table of contents is regenerated when merging", func(t *testing.T) {
+		loaded := NewMonthArchiveTemplate(opts, templatetest.Date)
+		err := loaded.Load(strings.NewReader(existingText))
+		require.NoError(t, err)
+
+		tgt := NewMonthArchiveTemplate(opts, templatetest.Date)
+		tgt.sections[1].contents = []contentItem{
+			{header: "[2020-12-19]", text: "text2"},
+		}
+		err = tgt.Merge(loaded)
+		require.NoError(t, err)
+		require.Equal(t, `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+3 dates archived (TestSection1: 2, TestSection2: 1, TestSection3: 1)
+- [2020-12-17] TestSection1, TestSection3
+- [2020-12-18] TestSection1
+- [2020-12-19] TestSection2
+
+_p_TestSection1_q_
+[2020-12-17]
+text1a
+[2020-12-18]
+text1b
+
+
+
+_p_TestSection2_q_
+[2020-12-19]
+text2
+
+
+
+_p_TestSection3_q_
+[2020-12-17]
+text3
+
+
+
+`, tgt.string())
+	})
+}
+
+func TestExtractDate(t *testing.T) {
+	archiveText := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1
+[2020-12-19]
+text2a
+  text2b
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+[2020-12-19]
+text3
+
+
+
+`
+
+	opts := templatetest.GetOpts()
+	archive := NewMonthArchiveTemplate(opts, templatetest.Date)
+	err := archive.Load(strings.NewReader(archiveText))
+	require.NoError(t, err)
+
+	t.Run("get dates", func(t *testing.T) {
+		require.Equal(t, []time.Time{
+			time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC),
+		}, archive.GetDates())
+	})
+
+	t.Run("extract date", func(t *testing.T) {
+		extracted := archive.ExtractDate(time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC))
+		expected := `-^-[Sat] 19 Dec 2020-v-
+
+_p_TestSection1_q_
+text2a
+  text2b
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+text3
+
+
+`
+		require.Equal(t, expected, extracted.string())
+	})
+
+	t.Run("extract date not in archive", func(t *testing.T) {
+		extracted := archive.ExtractDate(time.Date(2020, 12, 20, 0, 0, 0, 0, time.UTC))
+		require.True(t, extracted.IsEmpty())
+	})
+
+	t.Run("remove date", func(t *testing.T) {
+		archive.RemoveDate(time.Date(2020, 12, 19, 0, 0, 0, 0, time.UTC))
+		expected := `ARCHIVEPREFIX Dec2020 ARCHIVESUFFIX
+
+_p_TestSection1_q_
+[2020-12-18]
+text1
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+		require.Equal(t, expected, archive.string())
+		require.Equal(t, []time.Time{time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC)}, archive.GetDates())
+	})
+
+	t.Run("remove last date", func(t *testing.T) {
+		archive.RemoveDate(time.Date(2020, 12, 18, 0, 0, 0, 0, time.UTC))
+		require.True(t, archive.IsEmpty())
+	})
+}
+
+func TestArchiveMerge(t *testing.T) {
+	opts := templatetest.GetOpts()
+	date := templatetest.Date
+
+	tgt := NewMonthArchiveTemplate(opts, date)
+	tgt.sections[0].contents = []contentItem{
+		{header: "[2020-12-18]", text: "new1"},
+	}
+	src := NewMonthArchiveTemplate(opts, date)
+	src.sections[0].contents = []contentItem{
+		{header: "[2020-12-17]", text: "existing1a"},
+		{header: "[2020-12-18]", text: "existing1b"},
+	}
+	src.sections[2].contents = []contentItem{
+		{header: "[2020-12-18]", text: "existing3"},
+	}
+
+	err := tgt.Merge(src)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []contentItem{
+		{header: "[2020-12-18]", text: "new1"},
+		{header: "[2020-12-17]", text: "existing1a"},
+	}, tgt.sections[0].contents)
+	require.Empty(t, tgt.sections[1].contents)
+	require.Empty(t, tgt.sections[2].contents)
+
+	// merging again does not duplicate contents
+	err = tgt.Merge(src)
+	require.NoError(t, err)
+	require.Len(t, tgt.sections[0].contents, 2)
+}
+
+func TestArchiveMergeWithAliases(t *testing.T) {
+	opts := templatetest.GetOpts()
+	opts.Section.Items = []config.SectionItem{
+		{Name: "TestSection1", Aliases: []string{"OldSection1"}},
+		{Name: "TestSection2"},
+		{Name: "TestSection3"},
+	}
+	date := templatetest.Date
+
+	tgt := NewMonthArchiveTemplate(opts, date)
+	tgt.sections[0].contents = []contentItem{
+		{header: "[2020-12-18]", text: "new1"},
+	}
+	src := NewMonthArchiveTemplate(opts, date)
+	src.sections[0].contents = []contentItem{
+		{header: "[2020-12-17]", text: "existing1"},
+	}
+
+	err := tgt.Merge(src)
+	require.NoError(t, err)
+	require.Equal(t, []contentItem{
+		{header: "[2020-12-18]", text: "new1"},
+		{header: "[2020-12-17]", text: "existing1"},
+	}, tgt.sections[0].contents)
+}
+
+func TestRemoveDuplicates(t *testing.T) {
+	opts := templatetest.GetOpts()
+	archive := NewMonthArchiveTemplate(opts, templatetest.Date)
+	archive.sections[0].contents = []contentItem{
+		{header: "", text: "unheadered"},
+		{header: "[2020-12-17]", text: "text1a"},
+		{header: "[2020-12-18]", text: "text1b"},
+		{header: "[2020-12-17]", text: "text1c"},
+	}
+	archive.sections[1].contents = []contentItem{
+		{header: "[2020-12-17]", text: "text2"},
+	}
+
+	archive.RemoveDuplicates()
+	require.Equal(t, []contentItem{
+		{header: "", text: "unheadered"},
+		{header: "[2020-12-18]", text: "text1b"},
+		{header: "[2020-12-17]", text: "text1c"},
+	}, archive.sections[0].contents)
+	require.Equal(t, []contentItem{
+		{header: "[2020-12-17]", text: "text2"},
+	}, archive.sections[1].contents)
+}
+
+func TestParseArchiveFileName(t *testing.T) {
+	type testCase struct {
+		fileName       string
+		expectedPeriod Period
+		expectedTime   time.Time
+		expectedOk     bool
+	}
+
+	tests := map[string]testCase{
+		"week archive": {
+			fileName:       "archive-2020-W53.txt",
+			expectedPeriod: PeriodWeek,
+			expectedTime:   time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"first week archive": {
+			fileName:       "archive-2021-W01.txt",
+			expectedPeriod: PeriodWeek,
+			expectedTime:   time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"month archive": {
+			fileName:       "archive-Dec2020.txt",
+			expectedPeriod: PeriodMonth,
+			expectedTime:   time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"quarter archive": {
+			fileName:       "archive-2020-Q4.txt",
+			expectedPeriod: PeriodQuarter,
+			expectedTime:   time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"year archive": {
+			fileName:       "archive-2020.txt",
+			expectedPeriod: PeriodYear,
+			expectedTime:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"gzip compressed archive": {
+			fileName:       "archive-Dec2020.txt.gz",
+			expectedPeriod: PeriodMonth,
+			expectedTime:   time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"zstd compressed archive": {
+			fileName:       "archive-2020-Q4.txt.zst",
+			expectedPeriod: PeriodQuarter,
+			expectedTime:   time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"archive with unknown compression": {
+			fileName:   "archive-Dec2020.txt.bz2",
+			expectedOk: false,
+		},
+		"week that does not exist": {
+			fileName:   "archive-2021-W53.txt",
+			expectedOk: false,
+		},
+		"quarter that does not exist": {
+			fileName:   "archive-2020-Q5.txt",
+			expectedOk: false,
+		},
+		"archive with mismatched extension": {
+			fileName:   "archive-Dec2020.foo",
+			expectedOk: false,
+		},
+		"archive with trailing characters": {
+			fileName:   "archive-2020-Q4x.txt",
+			expectedOk: false,
+		},
+		"template file": {
+			fileName:   "2020-12-20.txt",
+			expectedOk: false,
+		},
+		"weekly template file": {
+			fileName:   "week-2020-12-14.txt",
+			expectedOk: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			period, parsedTime, ok := ParseArchiveFileName(test.fileName, templatetest.GetOpts())
+			require.Equal(t, test.expectedOk, ok)
+			if test.expectedOk {
+				require.Equal(t, test.expectedPeriod, period)
+				require.Equal(t, test.expectedTime, parsedTime)
+			}
+		})
+	}
+}
+
+func TestParseSectionArchiveFileName(t *testing.T) {
+	type testCase struct {
+		fileName        string
+		expectedSection string
+		expectedPeriod  Period
+		expectedTime    time.Time
+		expectedOk      bool
+	}
+
+	tests := map[string]testCase{
+		"month archive of section": {
+			fileName:        "archive-testsection2-Dec2020.txt",
+			expectedSection: "TestSection2",
+			expectedPeriod:  PeriodMonth,
+			expectedTime:    time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:      true,
+		},
+		"compressed week archive of section": {
+			fileName:        "archive-testsection3-2020-W53.txt.gz",
+			expectedSection: "TestSection3",
+			expectedPeriod:  PeriodWeek,
+			expectedTime:    time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC),
+			expectedOk:      true,
+		},
+		"archive of unknown section": {
+			fileName:   "archive-unknown-Dec2020.txt",
+			expectedOk: false,
+		},
+		"archive of all sections": {
+			fileName:   "archive-Dec2020.txt",
+			expectedOk: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			section, period, parsedTime, ok := ParseSectionArchiveFileName(test.fileName, templatetest.GetOpts())
+			require.Equal(t, test.expectedOk, ok)
+			if test.expectedOk {
+				require.Equal(t, test.expectedSection, section)
+				require.Equal(t, test.expectedPeriod, period)
+				require.Equal(t, test.expectedTime, parsedTime)
+			}
+		})
+	}
+}
+
 func TestIsArchiveItemHeader(t *testing.T) {
 	type testCase struct {
 		header   string
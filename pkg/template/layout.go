@@ -0,0 +1,29 @@
+package template
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// expandLayout replaces the placeholders of a layout with the corresponding components of a date
+func expandLayout(layout string, date time.Time) string {
+	if layout == "" {
+		return ""
+	}
+	r := strings.NewReplacer(
+		"{{year}}", date.Format("2006"),
+		"{{month}}", date.Format("01"),
+		"{{day}}", date.Format("02"),
+	)
+	return filepath.Clean(filepath.FromSlash(r.Replace(layout)))
+}
+
+// GetLayoutDepth returns the number of nested directories of a layout
+func GetLayoutDepth(layout string) int {
+	layout = strings.Trim(filepath.ToSlash(filepath.Clean(filepath.FromSlash(layout))), "/")
+	if layout == "" || layout == "." {
+		return 0
+	}
+	return strings.Count(layout, "/") + 1
+}
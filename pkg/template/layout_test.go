@@ -0,0 +1,77 @@
+package template
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandLayout(t *testing.T) {
+	type testCase struct {
+		layout   string
+		expected string
+	}
+
+	tests := map[string]testCase{
+		"empty layout": {
+			layout:   "",
+			expected: "",
+		},
+		"year and month": {
+			layout:   "{{year}}/{{month}}/",
+			expected: filepath.Join("2020", "12"),
+		},
+		"year, month, and day": {
+			layout:   "{{year}}/{{month}}/{{day}}",
+			expected: filepath.Join("2020", "12", "20"),
+		},
+		"placeholders with text": {
+			layout:   "notes-{{year}}/m{{month}}",
+			expected: filepath.Join("notes-2020", "m12"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, expandLayout(test.layout, templatetest.Date))
+		})
+	}
+}
+
+func TestGetLayoutDepth(t *testing.T) {
+	type testCase struct {
+		layout   string
+		expected int
+	}
+
+	tests := map[string]testCase{
+		"empty layout": {
+			layout:   "",
+			expected: 0,
+		},
+		"single directory": {
+			layout:   "{{year}}",
+			expected: 1,
+		},
+		"single directory with trailing slash": {
+			layout:   "{{year}}/",
+			expected: 1,
+		},
+		"nested directories": {
+			layout:   "{{year}}/{{month}}/",
+			expected: 2,
+		},
+		"deeply nested directories": {
+			layout:   "{{year}}/{{month}}/{{day}}",
+			expected: 3,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, GetLayoutDepth(test.layout))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package template
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/config"
+)
+
+// Period is the span of time covered by a dated note
+type Period int
+
+const (
+	// PeriodDay is the period of a daily note
+	PeriodDay Period = iota
+	// PeriodWeek is the period of a weekly note, keyed to an ISO week starting on Monday
+	PeriodWeek
+	// PeriodMonth is the period of a monthly note, keyed to a calendar month
+	PeriodMonth
+	// PeriodQuarter is a calendar quarter, used only for archives
+	PeriodQuarter
+	// PeriodYear is a calendar year, used only for archives
+	PeriodYear
+)
+
+// periods lists all periods for which a note can be created
+var periods = []Period{PeriodDay, PeriodWeek, PeriodMonth}
+
+// archivePeriods lists all periods by which notes can be archived
+var archivePeriods = []Period{PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear}
+
+// GetArchivePeriods returns all periods by which notes can be archived
+func GetArchivePeriods() []Period {
+	return append([]Period{}, archivePeriods...)
+}
+
+// String returns the name of a period
+func (p Period) String() string {
+	switch p {
+	case PeriodWeek:
+		return "week"
+	case PeriodMonth:
+		return "month"
+	case PeriodQuarter:
+		return "quarter"
+	case PeriodYear:
+		return "year"
+	default:
+		return "day"
+	}
+}
+
+// ParseArchivePeriod returns the Period for the name of an archive period
+func ParseArchivePeriod(name string) (Period, error) {
+	for _, p := range archivePeriods {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return PeriodMonth, fmt.Errorf("unknown archive period [%s]", name)
+}
+
+// Start returns the first day of the period containing the specified date
+func (p Period) Start(date time.Time) time.Time {
+	switch p {
+	case PeriodWeek:
+		// ISO weeks start on Monday
+		daysSinceMonday := (int(date.Weekday()) + 6) % 7
+		return time.Date(date.Year(), date.Month(), date.Day()-daysSinceMonday, 0, 0, 0, 0, date.Location())
+	case PeriodMonth:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	case PeriodQuarter:
+		firstMonth := time.Month(3*((int(date.Month())-1)/3) + 1)
+		return time.Date(date.Year(), firstMonth, 1, 0, 0, 0, 0, date.Location())
+	case PeriodYear:
+		return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, date.Location())
+	default:
+		return date
+	}
+}
+
+// End returns the first day after the period containing the specified date
+func (p Period) End(date time.Time) time.Time {
+	start := p.Start(date)
+	switch p {
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	case PeriodQuarter:
+		return start.AddDate(0, 3, 0)
+	case PeriodYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// getPeriodOpts returns a copy of opts with the file name format, header format, and sections replaced by those
+// configured for the specified period
+func getPeriodOpts(opts config.Opts, p Period) config.Opts {
+	var periodOpts config.PeriodOpts
+	switch p {
+	case PeriodWeek:
+		periodOpts = opts.Periods.Weekly
+	case PeriodMonth:
+		periodOpts = opts.Periods.Monthly
+	default:
+		return opts
+	}
+
+	opts.File.TimeFormat = periodOpts.FileTimeFormat
+	opts.Header.TimeFormat = periodOpts.HeaderTimeFormat
+	opts.Section.Names = periodOpts.Sections
+	// sections configured as items are the sections of daily notes
+	opts.Section.Items = nil
+	return opts
+}
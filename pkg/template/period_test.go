@@ -0,0 +1,150 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dkaslovsky/textnote/pkg/template/templatetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodStart(t *testing.T) {
+	type testCase struct {
+		period   Period
+		date     time.Time
+		expected time.Time
+	}
+
+	tests := map[string]testCase{
+		"day": {
+			period:   PeriodDay,
+			date:     time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC),
+		},
+		"week from monday": {
+			period:   PeriodWeek,
+			date:     time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"week from wednesday": {
+			period:   PeriodWeek,
+			date:     time.Date(2021, 2, 3, 12, 30, 0, 0, time.UTC),
+			expected: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"week from sunday": {
+			period:   PeriodWeek,
+			date:     time.Date(2021, 2, 7, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"week spanning years": {
+			period:   PeriodWeek,
+			date:     time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC),
+		},
+		"month": {
+			period:   PeriodMonth,
+			date:     time.Date(2021, 2, 17, 12, 30, 0, 0, time.UTC),
+			expected: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"quarter": {
+			period:   PeriodQuarter,
+			date:     time.Date(2021, 9, 30, 12, 30, 0, 0, time.UTC),
+			expected: time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"year": {
+			period:   PeriodYear,
+			date:     time.Date(2021, 9, 30, 12, 30, 0, 0, time.UTC),
+			expected: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.period.Start(test.date))
+		})
+	}
+}
+
+func TestPeriodEnd(t *testing.T) {
+	date := time.Date(2020, 12, 30, 12, 30, 0, 0, time.UTC)
+
+	tests := map[Period]time.Time{
+		PeriodDay:     time.Date(2020, 12, 31, 12, 30, 0, 0, time.UTC),
+		PeriodWeek:    time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+		PeriodMonth:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodQuarter: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodYear:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for period, expected := range tests {
+		t.Run(period.String(), func(t *testing.T) {
+			require.Equal(t, expected, period.End(date))
+		})
+	}
+}
+
+func TestParseArchivePeriod(t *testing.T) {
+	for _, period := range []Period{PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear} {
+		parsed, err := ParseArchivePeriod(period.String())
+		require.NoError(t, err)
+		require.Equal(t, period, parsed)
+	}
+
+	_, err := ParseArchivePeriod("day")
+	require.Error(t, err)
+}
+
+func TestNewPeriodTemplate(t *testing.T) {
+	type testCase struct {
+		period           Period
+		expectedDate     time.Time
+		expectedSections []*section
+		expectedFilePath string
+		expectedHeader   string
+	}
+
+	tests := map[string]testCase{
+		"day": {
+			period:       PeriodDay,
+			expectedDate: templatetest.Date,
+			expectedSections: []*section{
+				newSection("TestSection1"),
+				newSection("TestSection2"),
+				newSection("TestSection3"),
+			},
+			expectedFilePath: "path/to/app/dir/2020-12-20.txt",
+			expectedHeader:   "-^-[Sun] 20 Dec 2020-v-\n\n",
+		},
+		"week": {
+			period:       PeriodWeek,
+			expectedDate: time.Date(2020, 12, 14, 0, 0, 0, 0, time.UTC),
+			expectedSections: []*section{
+				newSection("TestWeekSection1"),
+				newSection("TestWeekSection2"),
+			},
+			expectedFilePath: "path/to/app/dir/week-2020-12-14.txt",
+			expectedHeader:   "-^-Week of [Mon] 14 Dec 2020-v-\n\n",
+		},
+		"month": {
+			period:       PeriodMonth,
+			expectedDate: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expectedSections: []*section{
+				newSection("TestMonthSection1"),
+				newSection("TestMonthSection2"),
+			},
+			expectedFilePath: "path/to/app/dir/month-2020-12.txt",
+			expectedHeader:   "-^-December 2020-v-\n\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template := NewPeriodTemplate(templatetest.GetOpts(), test.period, templatetest.Date)
+			require.Equal(t, test.period, template.GetPeriod())
+			require.Equal(t, test.expectedDate, template.GetDate())
+			require.Equal(t, test.expectedSections, template.sections)
+			require.Equal(t, test.expectedFilePath, template.GetFilePath())
+			require.Equal(t, test.expectedHeader, template.makeHeader())
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/dkaslovsky/textnote/pkg/config"
 	"github.com/pkg/errors"
@@ -28,6 +29,31 @@ func (s *section) deleteContents() {
 	s.contents = []contentItem{}
 }
 
+// appendText appends text to the end of the section's contents, retaining the section's trailing newlines or
+// adding the specified number of trailing newlines to a section without content
+func (s *section) appendText(text string, trailingNewlines int) {
+	text = strings.TrimRight(text, "\n")
+
+	if s.isEmpty() {
+		if trailingNewlines < 1 {
+			trailingNewlines = 1
+		}
+		s.contents = []contentItem{{text: text + strings.Repeat("\n", trailingNewlines)}}
+		return
+	}
+
+	last := &s.contents[len(s.contents)-1]
+	body := strings.TrimRight(last.text, "\n")
+	trailing := last.text[len(body):]
+	if trailing == "" {
+		trailing = "\n"
+	}
+	if body != "" {
+		body += "\n"
+	}
+	last.text = body + text + trailing
+}
+
 func (s *section) sortContents() {
 	// stable sort to preserve order for empty header case
 	sort.SliceStable(s.contents, func(i, j int) bool {
@@ -48,16 +74,25 @@ func (s *section) getNameString(prefix string, suffix string) string {
 	return fmt.Sprintf("%s%s%s\n", prefix, s.name, suffix)
 }
 
+// getText returns the text of the section's contents, without headers, as a single string
+func (s *section) getText() string {
+	sb := strings.Builder{}
+	for _, content := range s.contents {
+		sb.WriteString(content.text)
+	}
+	return sb.String()
+}
+
 func (s *section) getContentString() string {
-	str := ""
+	sb := strings.Builder{}
 	for _, content := range s.contents {
 		txt := content.string()
+		sb.WriteString(txt)
 		if !strings.HasSuffix(txt, "\n") {
-			txt += "\n"
+			sb.WriteString("\n")
 		}
-		str += txt
 	}
-	return str
+	return sb.String()
 }
 
 type contentItem struct {
@@ -89,7 +124,7 @@ func parseSection(text string, opts config.Opts) (*section, error) {
 		lines[1:],
 		opts.Archive.SectionContentPrefix,
 		opts.Archive.SectionContentSuffix,
-		opts.File.TimeFormat,
+		opts.Archive.SectionContentTimeFormat,
 	)
 
 	// return section populated with contents if any contentItem is non-empty
@@ -149,11 +184,19 @@ func stripPrefixSuffix(line string, prefix string, suffix string) string {
 	return strings.TrimPrefix(strings.TrimSuffix(line, suffix), prefix)
 }
 
+// sectionNameRegexes caches compiled section name regexes by pattern since every template loaded with the same
+// configuration uses the same regex
+var sectionNameRegexes sync.Map
+
 func getSectionNameRegex(prefix string, suffix string) (*regexp.Regexp, error) {
 	sectionPattern := fmt.Sprintf("%s.*%s", prefix, suffix)
+	if cached, found := sectionNameRegexes.Load(sectionPattern); found {
+		return cached.(*regexp.Regexp), nil
+	}
 	sectionNameRegex, err := regexp.Compile(sectionPattern)
 	if err != nil {
 		return sectionNameRegex, fmt.Errorf("invalid section prefix [%s] or suffix [%s]", prefix, suffix)
 	}
+	sectionNameRegexes.Store(sectionPattern, sectionNameRegex)
 	return sectionNameRegex, nil
 }
@@ -3,6 +3,7 @@ package template
 import (
 	"fmt"
 	"io"
+	"math"
 	"path/filepath"
 	"strings"
 	"time"
@@ -14,8 +15,10 @@ import (
 type Template struct {
 	opts       config.Opts
 	date       time.Time
+	period     Period
+	name       string // name of a note outside of the date scheme, empty for dated notes
 	sections   []*section
-	sectionIdx map[string]int // map of section name to index in sections slice
+	sectionIdx map[string]int // map of section name and aliases to index in sections slice
 }
 
 // NewTemplate constructs a new Template
@@ -30,6 +33,32 @@ func NewTemplate(opts config.Opts, date time.Time) *Template {
 		t.sections = append(t.sections, newSection(sectionName))
 		t.sectionIdx[sectionName] = idx
 	}
+	// map aliases to their sections, which are read from and written to notes by name
+	for idx, sectionName := range opts.Section.Names {
+		item, _ := opts.Section.GetItem(sectionName)
+		for _, alias := range item.Aliases {
+			if _, found := t.sectionIdx[alias]; !found {
+				t.sectionIdx[alias] = idx
+			}
+		}
+	}
+	return t
+}
+
+// NewPeriodTemplate constructs a new Template for the period containing the specified date
+func NewPeriodTemplate(opts config.Opts, period Period, date time.Time) *Template {
+	if period == PeriodDay {
+		return NewTemplate(opts, date)
+	}
+	t := NewTemplate(getPeriodOpts(opts, period), period.Start(date))
+	t.period = period
+	return t
+}
+
+// NewNamedTemplate constructs a new Template for a named note that is not associated with a date
+func NewNamedTemplate(opts config.Opts, name string) *Template {
+	t := NewTemplate(opts, time.Time{})
+	t.name = name
 	return t
 }
 
@@ -44,14 +73,50 @@ func (t *Template) GetDate() time.Time {
 	return t.date
 }
 
-// GetFileCursorLine returns the line at which to place the cursor when opening the template
+// GetPeriod returns the template's period
+func (t *Template) GetPeriod() Period {
+	return t.period
+}
+
+// GetName returns the template's name, which is empty for dated templates
+func (t *Template) GetName() string {
+	return t.name
+}
+
+// GetFileCursorLine returns the line at which to place the cursor when opening the template, which is in the section
+// configured for the cursor, at the first line of its contents or after its last line of contents as configured by
+// the cursor position, or otherwise the configured line
 func (t *Template) GetFileCursorLine() int {
+	idx, found := t.sectionIdx[t.opts.GetCursorSection()]
+	if !found {
+		return t.opts.File.CursorLine
+	}
+
+	line := strings.Count(t.makeHeader(), "\n")
+	for i, section := range t.sections {
+		line++ // line of the section name
+		if i != idx {
+			line += strings.Count(t.getBody(section), "\n")
+			continue
+		}
+		if t.opts.File.CursorPosition != config.CursorPositionEnd || section.isEmpty() {
+			return line + 1
+		}
+		// the line after the last line of contents, within the section's trailing newlines if there are any
+		contentLines := strings.Count(strings.TrimRight(section.getContentString(), "\n"), "\n") + 1
+		bodyLines := strings.Count(t.getBody(section), "\n")
+		return line + min(contentLines+1, bodyLines)
+	}
 	return t.opts.File.CursorLine
 }
 
-// GetFilePath generates a full path for a file based on the template date
+// GetFilePath generates a full path for a file based on the template date and the configured layout or,
+// for a named template, the template name
 func (t *Template) GetFilePath() string {
-	name := filepath.Join(t.opts.AppDir, t.date.Format(t.opts.File.TimeFormat))
+	name := filepath.Join(t.opts.AppDir, expandLayout(t.opts.File.Layout, t.date), t.date.Format(t.opts.File.TimeFormat))
+	if t.name != "" {
+		name = filepath.Join(t.opts.AppDir, t.opts.File.NotesDir, t.name)
+	}
 	if t.opts.File.Ext == "" {
 		return name
 	}
@@ -78,6 +143,27 @@ func (t *Template) CopySectionContents(src sectionGettable, sectionName string)
 	return nil
 }
 
+// AppendSectionContents appends text to the end of the contents of the specified section
+func (t *Template) AppendSectionContents(sectionName string, text string) error {
+	sec, err := t.getSection(sectionName)
+	if err != nil {
+		return fmt.Errorf("cannot append to section: %w", err)
+	}
+	sec.appendText(text, t.opts.Section.GetTrailingNewlines(sec.name))
+	return nil
+}
+
+// AddDefaultContents adds the configured default content to each empty section
+func (t *Template) AddDefaultContents() {
+	for _, sec := range t.sections {
+		item, _ := t.opts.Section.GetItem(sec.name)
+		if item.DefaultContent == "" || !sec.isEmpty() {
+			continue
+		}
+		sec.appendText(item.DefaultContent, t.opts.Section.GetTrailingNewlines(sec.name))
+	}
+}
+
 // DeleteSectionContents deletes the contents of a specified section
 func (t *Template) DeleteSectionContents(sectionName string) error {
 	sec, err := t.getSection(sectionName)
@@ -88,6 +174,12 @@ func (t *Template) DeleteSectionContents(sectionName string) error {
 	return nil
 }
 
+// HasSection returns whether the template has a section of a name or alias
+func (t *Template) HasSection(name string) bool {
+	_, found := t.sectionIdx[name]
+	return found
+}
+
 // IsEmpty evaluates if a template is empty (ignores whitespace)
 func (t *Template) IsEmpty() bool {
 	for _, sec := range t.sections {
@@ -133,6 +225,8 @@ func (t *Template) Load(r io.Reader) error {
 		if !found {
 			return fmt.Errorf("cannot load undefined section [%s]", section.name)
 		}
+		// a section read by an alias is renamed
+		section.name = t.sections[idx].name
 		t.sections[idx] = section
 	}
 
@@ -140,23 +234,32 @@ func (t *Template) Load(r io.Reader) error {
 }
 
 func (t *Template) string() string {
-	str := t.makeHeader()
+	sb := strings.Builder{}
+	sb.WriteString(t.makeHeader())
 	for _, section := range t.sections {
-		name := section.getNameString(t.opts.Section.Prefix, t.opts.Section.Suffix)
-		body := section.getContentString()
-		// default to trailing whitespace for empty body
-		if len(body) == 0 {
-			body = strings.Repeat("\n", t.opts.Section.TrailingNewlines)
-		}
-		str += fmt.Sprintf("%s%s", name, body)
+		sb.WriteString(section.getNameString(t.opts.Section.Prefix, t.opts.Section.Suffix))
+		sb.WriteString(t.getBody(section))
+	}
+	return sb.String()
+}
+
+func (t *Template) getBody(section *section) string {
+	body := section.getContentString()
+	// default to trailing whitespace for empty body
+	if len(body) == 0 {
+		body = strings.Repeat("\n", t.opts.Section.GetTrailingNewlines(section.name))
 	}
-	return str
+	return body
 }
 
 func (t *Template) makeHeader() string {
+	title := t.date.Format(t.opts.Header.TimeFormat)
+	if t.name != "" {
+		title = t.name
+	}
 	return fmt.Sprintf("%s%s%s\n%s",
 		t.opts.Header.Prefix,
-		t.date.Format(t.opts.Header.TimeFormat),
+		title,
 		t.opts.Header.Suffix,
 		strings.Repeat("\n", t.opts.Header.TrailingNewlines),
 	)
@@ -170,22 +273,96 @@ func (t *Template) getSection(name string) (*section, error) {
 	return t.sections[idx], nil
 }
 
-// ParseTemplateFileName extracts a time.Time from a file name and returns an additional
+// ValidateNoteName returns an error if a name cannot be used for a named note
+func ValidateNoteName(name string) error {
+	if name == "" || strings.TrimSpace(name) != name {
+		return fmt.Errorf("note name [%s] must be non-empty without leading or trailing whitespace", name)
+	}
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("note name [%s] must not contain path separators or begin with a dot", name)
+	}
+	return nil
+}
+
+// ParseNoteFileName extracts a note name from the name of a file in the named notes directory and returns an
+// additional bool indicating if the file name corresponds to a named note
+func ParseNoteFileName(fileName string, opts config.FileOpts) (string, bool) {
+	if strings.HasPrefix(fileName, ".") {
+		return "", false
+	}
+	if opts.Ext == "" {
+		return fileName, true
+	}
+	ext := fmt.Sprintf(".%s", opts.Ext)
+	if !strings.HasSuffix(fileName, ext) || fileName == ext {
+		return "", false
+	}
+	return strings.TrimSuffix(fileName, ext), true
+}
+
+// ParseTemplateFileName extracts a time.Time and Period from a file name and returns an additional
 // bool indicating if name corresponds to a valid template file name
-func ParseTemplateFileName(fileName string, opts config.FileOpts) (t time.Time, ok bool) {
+//
+// The file name is a path relative to the application directory and is only valid if it is located in
+// the directory specified by the configured layout for the parsed date.
+func ParseTemplateFileName(fileName string, opts config.Opts) (p Period, t time.Time, ok bool) {
+	dir, fileName := filepath.Split(fileName)
+
 	// ensure extension matches template file name convention
 	ext := filepath.Ext(fileName)
 	if ext == "." {
-		return t, false
+		return p, t, false
 	}
-	if strings.TrimPrefix(ext, ".") != opts.Ext {
-		return t, false
+	if strings.TrimPrefix(ext, ".") != opts.File.Ext {
+		return p, t, false
 	}
 
 	baseName := strings.TrimSuffix(fileName, ext)
-	t, err := time.Parse(opts.TimeFormat, baseName)
-	if err != nil {
-		return t, false
+	for _, p := range periods {
+		format := getPeriodOpts(opts, p).File.TimeFormat
+		t, err := time.Parse(format, baseName)
+		if err != nil {
+			continue
+		}
+		// require the name to be exactly that generated for the period so that no period is mistaken for another
+		if p.Start(t).Format(format) != baseName {
+			continue
+		}
+		// require the file to be located according to the layout
+		if filepath.Clean(dir) != filepath.Clean(expandLayout(opts.File.Layout, t)) {
+			continue
+		}
+		return p, t, true
+	}
+	return p, t, false
+}
+
+// GetLatestTemplateFile returns the template file of a period dated nearest to, but not after, a time from a list of
+// file names relative to the application directory, along with the number of template files of any period in the list
+func GetLatestTemplateFile(files []string, now time.Time, opts config.Opts, period Period) (string, int) {
+	latest := ""
+	delta := math.Inf(1)
+	numTemplateFiles := 0
+
+	for _, f := range files {
+		filePeriod, fileTime, ok := ParseTemplateFileName(f, opts)
+		if !ok {
+			// skip archive files and other non-template files that cannot be parsed
+			continue
+		}
+		numTemplateFiles++
+		if filePeriod != period {
+			continue
+		}
+		curdelta := now.Sub(fileTime).Hours()
+		if curdelta < 0 {
+			continue
+		}
+		if curdelta < delta {
+			delta = curdelta
+			latest = f
+		}
 	}
-	return t, true
+
+	return latest, numTemplateFiles
 }
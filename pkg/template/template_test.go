@@ -91,6 +91,50 @@ func TestGetFilePath(t *testing.T) {
 			fmt.Sprintf("%s/", opts.AppDir), ""),
 		)
 	})
+
+	t.Run("get file path with layout", func(t *testing.T) {
+		opts := templatetest.GetOpts()
+		opts.File.Layout = "{{year}}/{{month}}/"
+		template := NewTemplate(opts, templatetest.Date)
+		require.Equal(t, "path/to/app/dir/2020/12/2020-12-20.txt", template.GetFilePath())
+	})
+
+	t.Run("get file path with layout for weekly template", func(t *testing.T) {
+		opts := templatetest.GetOpts()
+		opts.File.Layout = "{{year}}/{{month}}/{{day}}"
+		template := NewPeriodTemplate(opts, PeriodWeek, templatetest.Date)
+		require.Equal(t, "path/to/app/dir/2020/12/14/week-2020-12-14.txt", template.GetFilePath())
+	})
+
+	t.Run("get file path with layout for named template", func(t *testing.T) {
+		opts := templatetest.GetOpts()
+		opts.File.Layout = "{{year}}/{{month}}/"
+		template := NewNamedTemplate(opts, "infra-migration")
+		require.Equal(t, "path/to/app/dir/notes/infra-migration.txt", template.GetFilePath())
+	})
+}
+
+func TestNewNamedTemplate(t *testing.T) {
+	opts := templatetest.GetOpts()
+	template := NewNamedTemplate(opts, "infra-migration")
+
+	require.Equal(t, "infra-migration", template.GetName())
+	require.Equal(t, "path/to/app/dir/notes/infra-migration.txt", template.GetFilePath())
+	require.Equal(t, `-^-infra-migration-v-
+
+_p_TestSection1_q_
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`, template.string())
 }
 
 func TestCopySectionContents(t *testing.T) {
@@ -219,6 +263,173 @@ func TestCopySectionContentsFail(t *testing.T) {
 	})
 }
 
+func TestAppendSectionContents(t *testing.T) {
+	type testCase struct {
+		templateText string
+		sectionName  string
+		text         string
+		expected     string
+	}
+
+	tests := map[string]testCase{
+		"append to empty section": {
+			templateText: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			sectionName: "TestSection2",
+			text:        "appended",
+			expected: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+
+
+
+_p_TestSection2_q_
+appended
+
+
+_p_TestSection3_q_
+
+
+
+`,
+		},
+		"append to section with content": {
+			templateText: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+text2
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			sectionName: "TestSection1",
+			text:        "appended",
+			expected: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+text2
+appended
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+		},
+		"append to last section with content without trailing newline": {
+			templateText: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+text3`,
+			sectionName: "TestSection3",
+			text:        "appended",
+			expected: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+text3
+appended
+`,
+		},
+		"append multiple lines with trailing newlines": {
+			templateText: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+			sectionName: "TestSection1",
+			text:        "appended1\nappended2\n\n",
+			expected: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+appended1
+appended2
+
+
+_p_TestSection2_q_
+
+
+
+_p_TestSection3_q_
+
+
+
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template := NewTemplate(templatetest.GetOpts(), templatetest.Date)
+			err := template.Load(strings.NewReader(test.templateText))
+			require.NoError(t, err)
+
+			err = template.AppendSectionContents(test.sectionName, test.text)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, template.string())
+		})
+	}
+
+	t.Run("append to section that does not exist", func(t *testing.T) {
+		template := NewTemplate(templatetest.GetOpts(), templatetest.Date)
+		err := template.AppendSectionContents("foobar", "appended")
+		require.Error(t, err)
+	})
+}
+
 func TestDeleteSectionContents(t *testing.T) {
 	t.Run("delete section with no contents", func(t *testing.T) {
 		toDelete := "sectionToBeDeleted"
@@ -715,10 +926,11 @@ text
 
 func TestParseTemplateFileName(t *testing.T) {
 	type testCase struct {
-		fileName     string
-		opts         config.FileOpts
-		expectedTime time.Time
-		expectedOk   bool
+		fileName       string
+		opts           config.FileOpts
+		expectedPeriod Period
+		expectedTime   time.Time
+		expectedOk     bool
 	}
 
 	tests := map[string]testCase{
@@ -796,19 +1008,338 @@ func TestParseTemplateFileName(t *testing.T) {
 			},
 			expectedOk: false,
 		},
+		"weekly file name": {
+			fileName: "week-2020-12-28.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+			},
+			expectedPeriod: PeriodWeek,
+			expectedTime:   time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"weekly file name not starting on monday": {
+			fileName: "week-2020-12-29.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+			},
+			expectedOk: false,
+		},
+		"monthly file name": {
+			fileName: "month-2020-12.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+			},
+			expectedPeriod: PeriodMonth,
+			expectedTime:   time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"file name in layout directory": {
+			fileName: "2020/12/2020-12-29.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+				Layout:     "{{year}}/{{month}}/",
+			},
+			expectedPeriod: PeriodDay,
+			expectedTime:   time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"weekly file name in layout directory": {
+			fileName: "2020/12/week-2020-12-28.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+				Layout:     "{{year}}/{{month}}",
+			},
+			expectedPeriod: PeriodWeek,
+			expectedTime:   time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC),
+			expectedOk:     true,
+		},
+		"file name in wrong layout directory": {
+			fileName: "2020/11/2020-12-29.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+				Layout:     "{{year}}/{{month}}/",
+			},
+			expectedOk: false,
+		},
+		"file name outside of layout directory": {
+			fileName: "2020-12-29.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+				Layout:     "{{year}}/{{month}}/",
+			},
+			expectedOk: false,
+		},
+		"file name in directory without layout": {
+			fileName: "notes/2020-12-29.txt",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+			},
+			expectedOk: false,
+		},
+		"monthly file name with mismatched extension": {
+			fileName: "month-2020-12.foo",
+			opts: config.FileOpts{
+				Ext:        "txt",
+				TimeFormat: "2006-01-02",
+			},
+			expectedOk: false,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			parsedTime, ok := ParseTemplateFileName(test.fileName, test.opts)
+			opts := templatetest.GetOpts()
+			opts.File = test.opts
+			period, parsedTime, ok := ParseTemplateFileName(test.fileName, opts)
 			require.Equal(t, test.expectedOk, ok)
 			if test.expectedOk {
+				require.Equal(t, test.expectedPeriod, period)
 				require.Equal(t, test.expectedTime, parsedTime)
 			}
 		})
 	}
 }
 
+func TestGetLatestTemplateFile(t *testing.T) {
+	opts := templatetest.GetOpts()
+
+	type testCase struct {
+		files            []string
+		now              time.Time
+		period           Period
+		expectedLatest   string
+		expectedNumFound int
+	}
+
+	tests := map[string]testCase{
+		"empty directory": {
+			files:            []string{},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedLatest:   "",
+			expectedNumFound: 0,
+		},
+		"no timestamped template files": {
+			files: []string{
+				"archive-Dec2019.txt",
+				"archive-2019-11-01.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedLatest:   "",
+			expectedNumFound: 0,
+		},
+		"single template file in future": {
+			files: []string{
+				"2020-04-13.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedLatest:   "",
+			expectedNumFound: 1,
+		},
+		"single template file": {
+			files: []string{
+				"2020-03-11.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedLatest:   "2020-03-11.txt",
+			expectedNumFound: 1,
+		},
+		"multiple template files": {
+			files: []string{
+				"2020-03-11.txt",
+				"2020-03-12.txt",
+				"2020-03-13.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedLatest:   "2020-03-13.txt",
+			expectedNumFound: 3,
+		},
+		"multiple template files with one in future": {
+			files: []string{
+				"2020-04-11.txt",
+				"2020-04-12.txt",
+				"2020-04-13.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedLatest:   "2020-04-12.txt",
+			expectedNumFound: 3,
+		},
+		"mix of timestamped template files and other files": {
+			files: []string{
+				".config",
+				"foobar",
+				"2020-03-11.txt",
+				"2020-03-12.txt",
+				"2020-03-13.txt",
+				"archive_April2020",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			expectedLatest:   "2020-03-13.txt",
+			expectedNumFound: 3,
+		},
+		"daily template files mixed with weekly and monthly template files": {
+			files: []string{
+				"2020-04-06.txt",
+				"2020-04-08.txt",
+				"week-2020-04-06.txt",
+				"month-2020-04.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			period:           PeriodDay,
+			expectedLatest:   "2020-04-08.txt",
+			expectedNumFound: 4,
+		},
+		"weekly template files mixed with daily and monthly template files": {
+			files: []string{
+				"2020-04-08.txt",
+				"week-2020-03-30.txt",
+				"week-2020-04-06.txt",
+				"week-2020-04-13.txt",
+				"month-2020-04.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			period:           PeriodWeek,
+			expectedLatest:   "week-2020-04-06.txt",
+			expectedNumFound: 5,
+		},
+		"monthly template files mixed with daily and weekly template files": {
+			files: []string{
+				"2020-04-08.txt",
+				"week-2020-04-06.txt",
+				"month-2020-03.txt",
+				"month-2020-04.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			period:           PeriodMonth,
+			expectedLatest:   "month-2020-04.txt",
+			expectedNumFound: 4,
+		},
+		"no weekly template files": {
+			files: []string{
+				"2020-04-08.txt",
+				"month-2020-04.txt",
+			},
+			now:              time.Date(2020, 4, 12, 0, 0, 0, 0, time.UTC),
+			period:           PeriodWeek,
+			expectedLatest:   "",
+			expectedNumFound: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			latest, numFound := GetLatestTemplateFile(test.files, test.now, opts, test.period)
+			require.Equal(t, test.expectedLatest, latest)
+			require.Equal(t, test.expectedNumFound, numFound)
+		})
+	}
+}
+
+func TestValidateNoteName(t *testing.T) {
+	type testCase struct {
+		name      string
+		shouldErr bool
+	}
+
+	tests := map[string]testCase{
+		"valid name": {
+			name:      "infra-migration",
+			shouldErr: false,
+		},
+		"valid name with spaces": {
+			name:      "infra migration",
+			shouldErr: false,
+		},
+		"empty name": {
+			name:      "",
+			shouldErr: true,
+		},
+		"name with surrounding whitespace": {
+			name:      " infra-migration ",
+			shouldErr: true,
+		},
+		"name with path separator": {
+			name:      "infra/migration",
+			shouldErr: true,
+		},
+		"name with parent directory": {
+			name:      "..",
+			shouldErr: true,
+		},
+		"hidden name": {
+			name:      ".infra-migration",
+			shouldErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateNoteName(test.name)
+			if test.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParseNoteFileName(t *testing.T) {
+	type testCase struct {
+		fileName     string
+		ext          string
+		expectedName string
+		expectedOk   bool
+	}
+
+	tests := map[string]testCase{
+		"file name with extension": {
+			fileName:     "infra-migration.txt",
+			ext:          "txt",
+			expectedName: "infra-migration",
+			expectedOk:   true,
+		},
+		"file name with no extension": {
+			fileName:     "infra-migration",
+			ext:          "",
+			expectedName: "infra-migration",
+			expectedOk:   true,
+		},
+		"file name with mismatched extension": {
+			fileName:   "infra-migration.foo",
+			ext:        "txt",
+			expectedOk: false,
+		},
+		"file name that is only extension": {
+			fileName:   ".txt",
+			ext:        "txt",
+			expectedOk: false,
+		},
+		"hidden file": {
+			fileName:   ".swp",
+			ext:        "",
+			expectedOk: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			noteName, ok := ParseNoteFileName(test.fileName, config.FileOpts{Ext: test.ext})
+			require.Equal(t, test.expectedOk, ok)
+			if test.expectedOk {
+				require.Equal(t, test.expectedName, noteName)
+			}
+		})
+	}
+}
+
 func TestIsEmpty(t *testing.T) {
 	type testCase struct {
 		templateFile string
@@ -879,3 +1410,179 @@ _p_TestSection3_q_
 		})
 	}
 }
+
+func TestSectionItems(t *testing.T) {
+	opts := templatetest.GetOpts()
+	opts.Section.Items = []config.SectionItem{
+		{Name: "TestSection1", Aliases: []string{"OldSection1"}, TrailingNewlines: 1},
+		{Name: "TestSection2", DefaultContent: "- [ ] ", Cursor: true},
+		{Name: "TestSection3"},
+	}
+
+	t.Run("load section by alias", func(t *testing.T) {
+		template := NewTemplate(opts, templatetest.Date)
+		err := template.Load(strings.NewReader(`-^-[Sun] 20 Dec 2020-v-
+
+_p_OldSection1_q_
+text1
+_p_TestSection2_q_
+text2
+_p_TestSection3_q_
+
+`))
+		require.NoError(t, err)
+		require.Equal(t, `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+_p_TestSection2_q_
+text2
+_p_TestSection3_q_
+
+
+
+`, template.string())
+	})
+
+	t.Run("append to section by alias", func(t *testing.T) {
+		template := NewTemplate(opts, templatetest.Date)
+		err := template.AppendSectionContents("OldSection1", "text")
+		require.NoError(t, err)
+		sec, err := template.getSection("TestSection1")
+		require.NoError(t, err)
+		require.Equal(t, "text\n", sec.getContentString())
+	})
+
+	t.Run("add default contents to empty sections", func(t *testing.T) {
+		template := NewTemplate(opts, templatetest.Date)
+		template.AddDefaultContents()
+		require.Equal(t, `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+
+_p_TestSection2_q_
+- [ ] 
+
+
+_p_TestSection3_q_
+
+
+
+`, template.string())
+	})
+
+	t.Run("default contents do not replace existing contents", func(t *testing.T) {
+		template := NewTemplate(opts, templatetest.Date)
+		err := template.AppendSectionContents("TestSection2", "text")
+		require.NoError(t, err)
+		template.AddDefaultContents()
+		sec, err := template.getSection("TestSection2")
+		require.NoError(t, err)
+		require.Equal(t, "text\n\n\n", sec.getContentString())
+	})
+
+	t.Run("cursor line at section contents", func(t *testing.T) {
+		template := NewTemplate(opts, templatetest.Date)
+		err := template.AppendSectionContents("TestSection1", "text1\ntext2")
+		require.NoError(t, err)
+		require.Equal(t, 7, template.GetFileCursorLine())
+	})
+
+	t.Run("cursor line without cursor section", func(t *testing.T) {
+		template := NewTemplate(templatetest.GetOpts(), templatetest.Date)
+		require.Equal(t, templatetest.GetOpts().File.CursorLine, template.GetFileCursorLine())
+	})
+}
+
+func TestGetFileCursorLine(t *testing.T) {
+	type testCase struct {
+		cursorSection  string
+		cursorPosition string
+		templateFile   string
+		expected       int
+	}
+
+	templateFile := `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+
+_p_TestSection2_q_
+text2a
+text2b
+
+
+
+_p_TestSection3_q_
+
+
+
+`
+
+	tests := map[string]testCase{
+		"no cursor section": {
+			cursorSection: "",
+			templateFile:  templateFile,
+			expected:      1,
+		},
+		"undefined cursor section": {
+			cursorSection: "undefined",
+			templateFile:  templateFile,
+			expected:      1,
+		},
+		"start of first section": {
+			cursorSection:  "TestSection1",
+			cursorPosition: config.CursorPositionStart,
+			templateFile:   templateFile,
+			expected:       4,
+		},
+		"start of section with contents": {
+			cursorSection:  "TestSection2",
+			cursorPosition: config.CursorPositionStart,
+			templateFile:   templateFile,
+			expected:       7,
+		},
+		"default position is start": {
+			cursorSection:  "TestSection2",
+			cursorPosition: "",
+			templateFile:   templateFile,
+			expected:       7,
+		},
+		"end of section with contents": {
+			cursorSection:  "TestSection2",
+			cursorPosition: config.CursorPositionEnd,
+			templateFile:   templateFile,
+			expected:       9,
+		},
+		"end of empty section": {
+			cursorSection:  "TestSection3",
+			cursorPosition: config.CursorPositionEnd,
+			templateFile:   templateFile,
+			expected:       13,
+		},
+		"end of section without trailing newlines": {
+			cursorSection:  "TestSection1",
+			cursorPosition: config.CursorPositionEnd,
+			templateFile: `-^-[Sun] 20 Dec 2020-v-
+
+_p_TestSection1_q_
+text1
+_p_TestSection2_q_
+_p_TestSection3_q_
+`,
+			expected: 4,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := templatetest.GetOpts()
+			opts.File.CursorSection = test.cursorSection
+			opts.File.CursorPosition = test.cursorPosition
+			template := NewTemplate(opts, templatetest.Date)
+			err := template.Load(strings.NewReader(test.templateFile))
+			require.NoError(t, err)
+			require.Equal(t, test.expected, template.GetFileCursorLine())
+		})
+	}
+}
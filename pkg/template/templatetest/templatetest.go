@@ -36,6 +36,7 @@ func GetOpts() config.Opts {
 			Ext:        "txt",
 			TimeFormat: "2006-01-02",
 			CursorLine: 1,
+			NotesDir:   "notes",
 		},
 		Archive: config.ArchiveOpts{
 			AfterDays:                7,
@@ -46,10 +47,29 @@ func GetOpts() config.Opts {
 			SectionContentSuffix:     "]",
 			SectionContentTimeFormat: "2006-01-02",
 			MonthTimeFormat:          "Jan2006",
+			Period:                   "month",
 		},
 		Cli: config.CliOpts{
 			TimeFormat: "2006-01-02",
 		},
+		Periods: config.PeriodsOpts{
+			Weekly: config.PeriodOpts{
+				FileTimeFormat:   "week-2006-01-02",
+				HeaderTimeFormat: "Week of [Mon] 02 Jan 2006",
+				Sections: []string{
+					"TestWeekSection1",
+					"TestWeekSection2",
+				},
+			},
+			Monthly: config.PeriodOpts{
+				FileTimeFormat:   "month-2006-01",
+				HeaderTimeFormat: "January 2006",
+				Sections: []string{
+					"TestMonthSection1",
+					"TestMonthSection2",
+				},
+			},
+		},
 		TemplateFileCountThresh: 90,
 	}
 